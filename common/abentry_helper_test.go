@@ -0,0 +1,50 @@
+package common_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+// Marshal the entry, unmarshal it into a fresh instance of the same type,
+// marshal it again and check both binaries are identical.
+// Every ABEntry implementation is expected to pass this check.
+func checkABEntryRoundTrip(t *testing.T, entry ABEntry) {
+	data, err := entry.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	entry2 := reflect.New(reflect.TypeOf(entry).Elem()).Interface().(ABEntry)
+	rest, err := entry2.UnmarshalBinaryData(data)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(rest) != 0 {
+		t.Errorf("%T left %v bytes unread", entry, len(rest))
+	}
+
+	data2, err := entry2.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(data, data2) {
+		t.Errorf("%T round trip mismatch: %X vs %X", entry, data, data2)
+	}
+	if uint64(len(data)) != entry.MarshalledSize() {
+		t.Errorf("%T MarshalledSize %v does not match %v bytes", entry, entry.MarshalledSize(), len(data))
+	}
+}
+
+func TestDBSignatureEntryRoundTrip(t *testing.T) {
+	sig := UnmarshalBinarySignature(bytes.Repeat([]byte{0x5a}, 96))
+	checkABEntryRoundTrip(t, NewDBSignatureEntry(Sha([]byte("identity")), sig))
+}
+
+func TestEndOfMinuteEntryRoundTrip(t *testing.T) {
+	block := new(AdminBlock)
+	block.AddEndOfMinuteMarker(3)
+	checkABEntryRoundTrip(t, block.ABEntries[0])
+}
@@ -6,21 +6,423 @@ package common
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/FactomProject/ed25519"
 )
 
+var ErrBlockNotFound = errors.New("Block not found")
+var ErrBlockSealed = errors.New("Block is sealed")
+var ErrBlockAlreadyExists = errors.New("Block already exists at this height")
+var ErrChainEmpty = errors.New("Chain has no blocks")
+var ErrIndexOutOfRange = errors.New("Index out of range")
+var ErrNotSplittableAtEOM = errors.New("Block can only be split after an end-of-minute marker")
+var ErrNoEOMFound = errors.New("Block has entries but no end-of-minute marker")
+var ErrSignatureOutOfPosition = errors.New("DB signature after an end-of-minute marker")
+var ErrInvalidMinute = errors.New("Minute 0 is not a valid end-of-minute marker")
+var ErrInvalidIdentity = errors.New("Identity chain id is zero or the admin chain id")
+var ErrNilIdentity = errors.New("DB signature entry has no identity")
+
 // Administrative Chain
 type AdminChain struct {
 	ChainID *Hash
 	Name    [][]byte
+	Blocks  []*AdminBlock
 
 	NextBlock       *AdminBlock
 	NextBlockHeight uint32
-	BlockMutex      sync.Mutex
+	BlockMutex      sync.RWMutex
+
+	subscribers      []chan AdminBlockEvent
+	subscribersMutex sync.Mutex
+}
+
+type AdminBlockEventType int
+
+// Admin block lifecycle events
+const (
+	BlockCreated   AdminBlockEventType = iota // New block created for the chain
+	BlockSealed                               // Block closed to new entries
+	BlockFinalized                            // Block added to the chain
+	BlockOrphaned                             // Block dropped from the chain
+)
+
+type AdminBlockEvent struct {
+	Type  AdminBlockEventType
+	Block *AdminBlock
+	Chain *AdminChain
+}
+
+// Buffer size of the channels returned by Events
+const adminBlockEventBuffer = 100
+
+// Subscribe to the block events of the chain.  Every call returns a new
+// channel receiving all subsequent events.  Events are dropped for a
+// subscriber whose channel buffer is full rather than stalling the chain.
+func (c *AdminChain) Events() <-chan AdminBlockEvent {
+	ch := make(chan AdminBlockEvent, adminBlockEventBuffer)
+
+	c.subscribersMutex.Lock()
+	c.subscribers = append(c.subscribers, ch)
+	c.subscribersMutex.Unlock()
+
+	return ch
+}
+
+// Send a block event to the subscribers of the chain
+func (c *AdminChain) Publish(eventType AdminBlockEventType, b *AdminBlock) {
+	event := AdminBlockEvent{Type: eventType, Block: b, Chain: c}
+
+	c.subscribersMutex.Lock()
+	defer c.subscribersMutex.Unlock()
+
+	for _, ch := range c.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Add ABlock to the chain in memory
+func (c *AdminChain) AddABlockToAChain(b *AdminBlock) (err error) {
+	c.BlockMutex.Lock()
+	defer c.BlockMutex.Unlock()
+
+	// Increase the slice length if needed
+	if b.Header.DBHeight >= uint32(len(c.Blocks)) {
+		temp := make([]*AdminBlock, b.Header.DBHeight+1, b.Header.DBHeight*2+1)
+		copy(temp, c.Blocks)
+		c.Blocks = temp
+	}
+
+	c.Blocks[b.Header.DBHeight] = b
+	c.Publish(BlockFinalized, b)
+
+	return nil
+}
+
+// Create an empty admin block at height linked to the stored block at
+// height-1, to backfill past heights.  NextBlock and NextBlockHeight are
+// left unchanged.
+func (c *AdminChain) NewBlockForHeight(height uint32) (b *AdminBlock, err error) {
+	c.BlockMutex.RLock()
+	defer c.BlockMutex.RUnlock()
+
+	if height < uint32(len(c.Blocks)) && c.Blocks[height] != nil {
+		return nil, ErrBlockAlreadyExists
+	}
+
+	b = new(AdminBlock)
+	b.Header = new(ABlockHeader)
+	b.Header.AdminChainID = c.ChainID
+
+	if height == 0 {
+		b.Header.PrevLedgerKeyMR = NewHash()
+	} else {
+		if height-1 >= uint32(len(c.Blocks)) || c.Blocks[height-1] == nil {
+			return nil, ErrBlockNotFound
+		}
+		err = b.SetPrevHashFrom(c.Blocks[height-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b.Header.DBHeight = height
+	b.ABEntries = make([]ABEntry, 0)
+	c.Publish(BlockCreated, b)
+
+	return b, nil
+}
+
+// Return the first block of the chain
+func (c *AdminChain) Genesis() (*AdminBlock, error) {
+	c.BlockMutex.RLock()
+	defer c.BlockMutex.RUnlock()
+
+	if len(c.Blocks) == 0 || c.Blocks[0] == nil {
+		return nil, ErrChainEmpty
+	}
+	return c.Blocks[0], nil
+}
+
+// Return the most recently finalized block of the chain
+func (c *AdminChain) Tip() (*AdminBlock, error) {
+	c.BlockMutex.RLock()
+	defer c.BlockMutex.RUnlock()
+
+	for i := len(c.Blocks) - 1; i >= 0; i-- {
+		if c.Blocks[i] != nil {
+			return c.Blocks[i], nil
+		}
+	}
+	return nil, ErrChainEmpty
+}
+
+// Key of the next block of the chain: the ChainID followed by the
+// big-endian NextBlockHeight, so that blocks sort by height under the chain
+func (c *AdminChain) NextBlockKey() []byte {
+	return AdminBlockHeightKey(c.ChainID.Bytes(), c.NextBlockHeight)
+}
+
+// Key of an admin block: the chain id followed by the big-endian height
+func AdminBlockHeightKey(chainID []byte, height uint32) []byte {
+	key := make([]byte, len(chainID)+4)
+	copy(key, chainID)
+	binary.BigEndian.PutUint32(key[len(chainID):], height)
+	return key
+}
+
+// Return the blocks of the chain with heights from and to inclusive
+func (c *AdminChain) ExportRange(from, to uint32) ([]*AdminBlock, error) {
+	if from > to {
+		return nil, fmt.Errorf("Invalid range %v to %v", from, to)
+	}
+
+	c.BlockMutex.RLock()
+	defer c.BlockMutex.RUnlock()
+
+	if to >= uint32(len(c.Blocks)) {
+		return nil, ErrBlockNotFound
+	}
+
+	blocks := make([]*AdminBlock, 0, to-from+1)
+	for height := from; height <= to; height++ {
+		if c.Blocks[height] == nil {
+			return nil, ErrBlockNotFound
+		}
+		blocks = append(blocks, c.Blocks[height])
+	}
+
+	return blocks, nil
+}
+
+// Inconsistency found by AdminChain.Validate or ValidateAdminBlockChain
+type ChainValidationError struct {
+	Height uint32
+	Reason string
+}
+
+func (e ChainValidationError) Error() string {
+	return fmt.Sprintf("Admin block %v: %v", e.Height, e.Reason)
+}
+
+// Every inconsistency found by AdminChain.Validate
+type ChainValidationErrors []ChainValidationError
+
+func (e ChainValidationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Check that the stored blocks form a consistent chain: heights are
+// consecutive from 0, each block links to its predecessor and any cached
+// hash matches the block contents.  Every violation is reported, as
+// ChainValidationErrors.
+func (c *AdminChain) Validate() error {
+	c.BlockMutex.RLock()
+	defer c.BlockMutex.RUnlock()
+
+	errs := make(ChainValidationErrors, 0)
+	fail := func(height uint32, format string, args ...interface{}) {
+		errs = append(errs, ChainValidationError{Height: height, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	for i, b := range c.Blocks {
+		height := uint32(i)
+		if b == nil {
+			fail(height, "missing")
+			continue
+		}
+		if b.Header == nil {
+			fail(height, "no header")
+			continue
+		}
+		if b.Header.DBHeight != height {
+			fail(height, "stored with DBHeight %v", b.Header.DBHeight)
+		}
+
+		if b.fullHash != nil || b.partialHash != nil {
+			data, err := b.MarshalBinary()
+			if err != nil {
+				fail(height, "%v", err)
+				continue
+			}
+			if b.fullHash != nil && !b.fullHash.IsSameAs(Sha512Half(data)) {
+				fail(height, "cached LedgerKeyMR does not match the block")
+			}
+			if b.partialHash != nil && !b.partialHash.IsSameAs(Sha(data)) {
+				fail(height, "cached partial hash does not match the block")
+			}
+		}
+
+		if i > 0 && c.Blocks[i-1] != nil && c.Blocks[i-1].Header != nil {
+			ok, err := b.PrevHashMatches(c.Blocks[i-1])
+			if err != nil {
+				fail(height, "%v", err)
+			} else if !ok {
+				fail(height, "does not link to block %v", i-1)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Check that a range of admin blocks, such as one received during sync,
+// forms a chain: the DBHeights are consecutive, each block links to the
+// hash of the previous one computed from its contents, they all belong to
+// the same chain and each passes Validate.  The first failure is returned
+// as a ChainValidationError.
+func ValidateAdminBlockChain(blocks []*AdminBlock) error {
+	var prevHash *Hash
+	for i, b := range blocks {
+		if b == nil || b.Header == nil {
+			return ChainValidationError{Reason: fmt.Sprintf("block %v of the range has no header", i)}
+		}
+		height := b.Header.DBHeight
+		fail := func(format string, args ...interface{}) error {
+			return ChainValidationError{Height: height, Reason: fmt.Sprintf(format, args...)}
+		}
+
+		if err := b.Validate(); err != nil {
+			return fail("%v", err)
+		}
+		if i > 0 {
+			prev := blocks[i-1]
+			if height != prev.Header.DBHeight+1 {
+				return fail("follows block %v", prev.Header.DBHeight)
+			}
+			if !sameHash(b.Header.AdminChainID, prev.Header.AdminChainID) {
+				return fail("belongs to chain %v", b.Header.AdminChainID)
+			}
+			if !sameHash(b.Header.PrevLedgerKeyMR, prevHash) {
+				return fail("does not link to block %v", prev.Header.DBHeight)
+			}
+		}
+
+		data, err := b.MarshalBinary()
+		if err != nil {
+			return fail("%v", err)
+		}
+		prevHash = Sha512Half(data)
+	}
+	return nil
+}
+
+var _ BinaryMarshallable = (*AdminChain)(nil)
+
+// Write out the metadata of the chain to binary: the ChainID, the count of
+// Name parts, each part prefixed with its length, and NextBlockHeight.
+// The blocks of the chain are not included.
+func (c *AdminChain) MarshalBinary() (data []byte, err error) {
+	if c.ChainID == nil {
+		return nil, errors.New("Chain has no ChainID")
+	}
+	data = make([]byte, 0, c.MarshalledSize())
+	data = append(data, c.ChainID.bytes[:]...)
+	data = AppendVarInt(data, uint64(len(c.Name)))
+	for _, part := range c.Name {
+		data = AppendVarInt(data, uint64(len(part)))
+		data = append(data, part...)
+	}
+	data = appendUint32(data, c.NextBlockHeight)
+	return data, nil
+}
+
+func (c *AdminChain) MarshalledSize() uint64 {
+	size := uint64(HASH_LENGTH)
+	size += uint64(len(AppendVarInt(nil, uint64(len(c.Name)))))
+	for _, part := range c.Name {
+		size += uint64(len(AppendVarInt(nil, uint64(len(part))))) + uint64(len(part))
+	}
+	size += 4 // NextBlockHeight
+	return size
+}
+
+func (c *AdminChain) UnmarshalBinaryData(data []byte) (newData []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Error unmarshalling: %v", r)
+		}
+	}()
+	newData = data
+	c.ChainID = new(Hash)
+	newData, err = c.ChainID.UnmarshalBinaryData(newData)
+	if err != nil {
+		return
+	}
+
+	var count uint64
+	count, newData = DecodeVarInt(newData)
+	if count > uint64(len(newData)) {
+		return nil, fmt.Errorf("Invalid Name part count %v for %v remaining bytes", count, len(newData))
+	}
+	c.Name = make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var size uint64
+		size, newData = DecodeVarInt(newData)
+		if size > uint64(len(newData)) {
+			return nil, fmt.Errorf("Invalid Name part size %v for %v remaining bytes", size, len(newData))
+		}
+		part := make([]byte, size)
+		copy(part, newData)
+		c.Name = append(c.Name, part)
+		newData = newData[size:]
+	}
+
+	c.NextBlockHeight, newData = binary.BigEndian.Uint32(newData[0:4]), newData[4:]
+	return
+}
+
+func (c *AdminChain) UnmarshalBinary(data []byte) (err error) {
+	_, err = c.UnmarshalBinaryData(data)
+	return
+}
+
+// Roll the chain back so that the block at toHeight is its tip: the blocks
+// above it are dropped and NextBlock is recreated on top of it
+func (c *AdminChain) Truncate(toHeight uint32) (err error) {
+	c.BlockMutex.Lock()
+	defer c.BlockMutex.Unlock()
+
+	if toHeight >= uint32(len(c.Blocks)) || c.Blocks[toHeight] == nil {
+		return ErrBlockNotFound
+	}
+
+	for i := toHeight + 1; i < uint32(len(c.Blocks)); i++ {
+		if c.Blocks[i] != nil {
+			c.Publish(BlockOrphaned, c.Blocks[i])
+		}
+		c.Blocks[i] = nil
+	}
+	c.Blocks = c.Blocks[:toHeight+1]
+
+	c.NextBlockHeight = toHeight + 1
+	c.NextBlock, err = CreateAdminBlock(c, c.Blocks[toHeight], 10)
+	return err
 }
 
 // Administrative Block
@@ -37,12 +439,58 @@ type AdminBlock struct {
 	//Not Marshalized
 	fullHash    *Hash //SHA512Half
 	partialHash *Hash //SHA256
+	sealed      bool
+	mutationLog *BlockMutationLog
+	Checksum    uint32 //CRC32 of the binary, set by CRC32Checksum
+	entryTypes  []byte //Cached by EntryTypes
+	hashMutex   sync.Mutex
+}
+
+// Record of the mutations of an admin block, for debugging
+type BlockMutationLog struct {
+	sync.Mutex
+	Events []BlockMutationEvent
+}
+
+type BlockMutationEvent struct {
+	Op        string
+	Timestamp time.Time
+	Callers   []uintptr
+}
+
+// Start recording the mutations of the admin block and return the log
+func (b *AdminBlock) EnableMutationLog() *BlockMutationLog {
+	if b.mutationLog == nil {
+		b.mutationLog = new(BlockMutationLog)
+	}
+	return b.mutationLog
+}
+
+func (b *AdminBlock) logMutation(op string) {
+	if b.mutationLog == nil {
+		return
+	}
+
+	callers := make([]uintptr, 32)
+	callers = callers[:runtime.Callers(2, callers)]
+
+	b.mutationLog.Lock()
+	b.mutationLog.Events = append(b.mutationLog.Events, BlockMutationEvent{
+		Op:        op,
+		Timestamp: time.Now(),
+		Callers:   callers})
+	b.mutationLog.Unlock()
 }
 
 var _ Printable = (*AdminBlock)(nil)
 var _ BinaryMarshallable = (*AdminBlock)(nil)
 
+// Return the LedgerKeyMR of the admin block, computing it on first use.
+// Concurrent callers share a single computation.
 func (ab *AdminBlock) LedgerKeyMR() (*Hash, error) {
+	ab.hashMutex.Lock()
+	defer ab.hashMutex.Unlock()
+
 	if ab.fullHash == nil {
 		err := ab.buildFullBHash()
 		if err != nil {
@@ -52,155 +500,1905 @@ func (ab *AdminBlock) LedgerKeyMR() (*Hash, error) {
 	return ab.fullHash, nil
 }
 
-func (ab *AdminBlock) PartialHash() (*Hash, error) {
-	if ab.partialHash == nil {
-		err := ab.buildPartialHash()
-		if err != nil {
-			return nil, err
+func (ab *AdminBlock) PartialHash() (*Hash, error) {
+	ab.hashMutex.Lock()
+	defer ab.hashMutex.Unlock()
+
+	if ab.partialHash == nil {
+		err := ab.buildPartialHash()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ab.partialHash, nil
+}
+
+// Create an empty Admin Block
+func CreateAdminBlock(chain *AdminChain, prev *AdminBlock, cap uint) (b *AdminBlock, err error) {
+	if prev == nil && chain.NextBlockHeight != 0 {
+		return nil, errors.New("Previous block cannot be nil")
+	} else if prev != nil && chain.NextBlockHeight == 0 {
+		return nil, errors.New("Origin block cannot have a parent block")
+	}
+
+	b = new(AdminBlock)
+
+	b.Header = new(ABlockHeader)
+	b.Header.AdminChainID = chain.ChainID
+
+	if prev == nil {
+		b.Header.PrevLedgerKeyMR = NewHash()
+	} else {
+		err = b.SetPrevHashFrom(prev)
+		if err != nil {
+			return
+		}
+	}
+
+	b.Header.DBHeight = chain.NextBlockHeight
+	b.ABEntries = make([]ABEntry, 0, cap)
+	chain.Publish(BlockCreated, b)
+
+	return b, err
+}
+
+// Link the admin block to its predecessor by setting PrevLedgerKeyMR
+// to the hash of prev and DBHeight to the height following prev.
+// The cached hashes are cleared since they cover the header.
+func (b *AdminBlock) SetPrevHashFrom(prev *AdminBlock) (err error) {
+	if prev == nil || prev.Header == nil {
+		return errors.New("Previous block cannot be nil")
+	}
+	if b.Header == nil {
+		b.Header = new(ABlockHeader)
+	}
+
+	b.Header.PrevLedgerKeyMR, err = prev.LedgerKeyMR()
+	if err != nil {
+		return
+	}
+	b.Header.DBHeight = prev.Header.DBHeight + 1
+	b.fullHash = nil
+	b.partialHash = nil
+
+	return
+}
+
+// Check whether the PrevLedgerKeyMR of the admin block is the hash of prev
+func (b *AdminBlock) PrevHashMatches(prev *AdminBlock) (bool, error) {
+	if prev == nil {
+		return false, errors.New("Previous block cannot be nil")
+	}
+	if b.Header == nil || b.Header.PrevLedgerKeyMR == nil {
+		return false, nil
+	}
+
+	prevHash, err := prev.LedgerKeyMR()
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(b.Header.PrevLedgerKeyMR.Bytes(), prevHash.Bytes()) == 1, nil
+}
+
+// Check whether the admin block directly follows parent in the chain
+func (b *AdminBlock) IsChildOf(parent *AdminBlock) (bool, error) {
+	return b.PrevHashMatches(parent)
+}
+
+// Check whether the admin block links to candidatePrev, for example to
+// tell whether competing blocks at the same height are on the same branch
+// during a reorganization
+func (b *AdminBlock) LinksTo(candidatePrev *AdminBlock) (bool, error) {
+	return b.PrevHashMatches(candidatePrev)
+}
+
+// Recompute both the SHA512Half and SHA256 hashes of the admin block,
+// refreshing MessageCount and BodySize from the entries first.
+// This breaks the guarantees of a sealed block and should only be called
+// while the block is still being built.
+func (b *AdminBlock) Rehash() (err error) {
+	b.hashMutex.Lock()
+	defer b.hashMutex.Unlock()
+
+	b.updateHeaderCounts()
+	err = b.buildFullBHash()
+	if err != nil {
+		return
+	}
+	return b.buildPartialHash()
+}
+
+// Digest signed by block-level signatures of the admin block.
+// It is the SHA256 of the block binary with every DB signature entry left
+// out: the header, with MessageCount and BodySize covering only the
+// remaining entries, followed by those entries in order.  Signatures can
+// therefore be added to the block without changing the digest.
+func (b *AdminBlock) SigningDigest() (*Hash, error) {
+	unsigned := new(AdminBlock)
+	header := *b.Header
+	unsigned.Header = &header
+	unsigned.ABEntries = b.FilterEntries(func(e ABEntry) bool {
+		return e.Type() != TYPE_DB_SIGNATURE
+	})
+
+	data, err := unsigned.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return Sha(data), nil
+}
+
+// Directory block body entry referencing the admin block: the
+// AdminChainID and the SHA256 of the admin block
+func (b *AdminBlock) dbReference() (*DBEntry, error) {
+	keyMR, err := b.PartialHash()
+	if err != nil {
+		return nil, err
+	}
+	return &DBEntry{ChainID: b.Header.AdminChainID, KeyMR: keyMR}, nil
+}
+
+// Write out the directory block body entry referencing the admin block,
+// exactly as AddABlockToDBEntry inserts it
+func (b *AdminBlock) MarshalDBReference() ([]byte, error) {
+	dbEntry, err := b.dbReference()
+	if err != nil {
+		return nil, err
+	}
+	return dbEntry.MarshalBinary()
+}
+
+// Build the SHA512Half hash for the admin block
+func (b *AdminBlock) buildFullBHash() (err error) {
+	b.logMutation("buildFullBHash")
+	var binaryAB []byte
+	binaryAB, err = b.MarshalBinary()
+	if err != nil {
+		return
+	}
+	b.fullHash = Sha512Half(binaryAB)
+	return
+}
+
+// Build the SHA256 hash for the admin block
+func (b *AdminBlock) buildPartialHash() (err error) {
+	var binaryAB []byte
+	binaryAB, err = b.MarshalBinary()
+	if err != nil {
+		return
+	}
+	b.partialHash = Sha(binaryAB)
+	return
+}
+
+// Compute the CRC32 (IEEE) checksum of the admin block binary and store it
+// in Checksum.  This is a cheap guard against storage corruption, not a
+// substitute for the block hashes.
+func (b *AdminBlock) CRC32Checksum() (uint32, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	b.Checksum = crc32.ChecksumIEEE(data)
+	return b.Checksum, nil
+}
+
+// Check the stored Checksum against the current binary of the admin block
+func (b *AdminBlock) VerifyChecksum() (bool, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return false, err
+	}
+	return crc32.ChecksumIEEE(data) == b.Checksum, nil
+}
+
+// Freeze the admin block so that no more entries can be added to it.
+// The block hashes are still available.
+func (b *AdminBlock) Seal() {
+	b.sealed = true
+}
+
+func (b *AdminBlock) IsSealed() bool {
+	return b.sealed
+}
+
+// Add an Admin Block entry to the block.
+// MessageCount and BodySize are updated and the cached hashes are cleared.
+func (b *AdminBlock) AddABEntry(e ABEntry) (err error) {
+	if b.sealed {
+		return ErrBlockSealed
+	}
+	b.logMutation("AddABEntry")
+	b.ABEntries = append(b.ABEntries, e)
+	b.entryTypes = nil
+	if b.Header != nil {
+		b.updateHeaderCounts()
+	}
+	b.fullHash = nil
+	b.partialHash = nil
+	return
+}
+
+// Append the entries of src to the admin block, for instance to merge a
+// partial block of DB signatures with one of end-of-minute markers.
+// MessageCount and BodySize are updated and the cached hashes are cleared.
+func (b *AdminBlock) AppendEntries(src *AdminBlock) error {
+	if b.sealed {
+		return ErrBlockSealed
+	}
+	if src == nil {
+		return errors.New("Source block cannot be nil")
+	}
+	if b.Header == nil {
+		return errors.New("Admin block has no header")
+	}
+	b.logMutation("AppendEntries")
+
+	b.ABEntries = append(b.ABEntries, src.ABEntries...)
+	b.entryTypes = nil
+	b.updateHeaderCounts()
+	b.fullHash = nil
+	b.partialHash = nil
+	return nil
+}
+
+// Add the end-of-minute marker into the admin block
+func (b *AdminBlock) AddEndOfMinuteMarker(eomType byte) (err error) {
+	if eomType == 0 {
+		return ErrInvalidMinute
+	}
+	eOMEntry := &EndOfMinuteEntry{
+		entryType: TYPE_MINUTE_NUM,
+		EOM_Type:  eomType}
+
+	return b.AddABEntry(eOMEntry)
+}
+
+// Sign the binary of the previous directory block header and add the
+// resulting DB signature entry into the admin block
+func (b *AdminBlock) AddDBSignature(identity *Hash, privKey []byte, prevDBHeader *DBlockHeader) (err error) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return fmt.Errorf("Invalid private key length of %v, want %v", len(privKey), ed25519.PrivateKeySize)
+	}
+	if prevDBHeader == nil {
+		return errors.New("Previous directory block header cannot be nil")
+	}
+	headerBytes, err := prevDBHeader.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	var pk PrivateKey
+	pk.AllocateNew()
+	copy(pk.Key[:], privKey)
+	copy(pk.Pub.Key[:], privKey[32:])
+
+	sig := pk.Sign(headerBytes)
+
+	return b.AddABEntry(NewDBSignatureEntry(identity, sig))
+}
+
+// Called, when set, with the name, byte size and duration of every
+// AdminBlock MarshalBinary and UnmarshalBinary, for metrics.  Set it before
+// any block is encoded or decoded; it is not synchronized.
+var ObserveOp func(op string, size int, dur time.Duration)
+
+// Write out the AdminBlock to binary.
+// MessageCount and BodySize are written from the live ABEntries slice, not
+// from the header fields, which marshalling leaves untouched.  An admin
+// block without entries therefore always serializes to its header alone,
+// with MessageCount 0 and BodySize 0.
+func (b *AdminBlock) MarshalBinary() (data []byte, err error) {
+	if ObserveOp != nil {
+		start := time.Now()
+		defer func() { ObserveOp("MarshalBinary", len(data), time.Since(start)) }()
+	}
+
+	return b.MarshalBinaryWithOptions(DefaultMarshalOptions)
+}
+
+// Options controlling how an admin block is encoded
+type MarshalOptions struct {
+	// Binary format version.  Only version 0, the current format, exists.
+	Version byte
+	// Append the big-endian CRC32 of the binary, as computed by
+	// CRC32Checksum, after the block.
+	IncludeChecksum bool
+	// Compress the entries.  No compressed format is defined yet, so
+	// setting it is an error.
+	CompressEntries bool
+}
+
+// Options used by MarshalBinary
+var DefaultMarshalOptions = MarshalOptions{}
+
+// Write out the AdminBlock to binary in the format selected by opts
+func (b *AdminBlock) MarshalBinaryWithOptions(opts MarshalOptions) ([]byte, error) {
+	if opts.Version != 0 {
+		return nil, fmt.Errorf("Unsupported admin block format version %v", opts.Version)
+	}
+	if opts.CompressEntries {
+		return nil, errors.New("Compressed admin block entries are not supported")
+	}
+
+	size, err := b.Size()
+	if err != nil {
+		return nil, err
+	}
+	if opts.IncludeChecksum {
+		size += 4
+	}
+	data, err := b.MarshalBinaryAppend(make([]byte, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	if opts.IncludeChecksum {
+		data = appendUint32(data, crc32.ChecksumIEEE(data))
+	}
+	return data, nil
+}
+
+// Append the binary of the AdminBlock to dst and return the extended slice.
+// The counts are written the same way as by MarshalBinary.
+func (b *AdminBlock) MarshalBinaryAppend(dst []byte) ([]byte, error) {
+	messageCount, bodySize := b.headerCounts()
+	dst = b.Header.appendBinaryWithCounts(dst, messageCount, bodySize)
+
+	for _, entry := range b.ABEntries {
+		data, err := entry.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, data...)
+	}
+	return dst, nil
+}
+
+// Write the binary of the AdminBlock at the start of buf and return the
+// number of bytes written, or io.ErrShortBuffer if buf is too small.
+func (b *AdminBlock) MarshalsTo(buf []byte) (int, error) {
+	size, err := b.Size()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(buf)) < size {
+		return 0, io.ErrShortBuffer
+	}
+	data, err := b.MarshalBinaryAppend(buf[:0:size])
+	if err != nil {
+		return 0, err
+	}
+	// An entry writing more than its MarshalledSize makes append move the
+	// data out of buf
+	if uint64(len(data)) > size {
+		return 0, fmt.Errorf("Admin block marshalled to %v bytes but Size is %v", len(data), size)
+	}
+	return len(data), nil
+}
+
+// Write out the AdminBlock to binary and compute its SHA256 hash in the
+// same pass.  The hash is also cached as the partial hash of the block.
+func (b *AdminBlock) MarshalAndHash() (data []byte, hash *Hash, err error) {
+	var buf bytes.Buffer
+	sha := sha256.New()
+	w := io.MultiWriter(&buf, sha)
+
+	messageCount, bodySize := b.headerCounts()
+	w.Write(b.Header.appendBinaryWithCounts(nil, messageCount, bodySize))
+	for _, entry := range b.ABEntries {
+		var entryData []byte
+		entryData, err = entry.MarshalBinary()
+		if err != nil {
+			return nil, nil, err
+		}
+		w.Write(entryData)
+	}
+
+	hash = new(Hash)
+	copy(hash.bytes[:], sha.Sum(nil))
+
+	b.hashMutex.Lock()
+	b.partialHash = hash
+	b.hashMutex.Unlock()
+	return buf.Bytes(), hash, nil
+}
+
+// Return MessageCount and BodySize computed from the live ABEntries slice
+func (b *AdminBlock) headerCounts() (messageCount uint32, bodySize uint32) {
+	var size uint64
+	for _, entry := range b.ABEntries {
+		size += entry.MarshalledSize()
+	}
+	return uint32(len(b.ABEntries)), uint32(size)
+}
+
+// Set MessageCount and BodySize from the live ABEntries slice
+func (b *AdminBlock) updateHeaderCounts() {
+	b.Header.MessageCount, b.Header.BodySize = b.headerCounts()
+}
+
+// Write out the header of the AdminBlock followed by its LedgerKeyMR, so
+// that the PrevLedgerKeyMR links of a chain can be checked before the
+// bodies are downloaded
+func (b *AdminBlock) MarshalHeaderSummary() ([]byte, error) {
+	hash, err := b.LedgerKeyMR()
+	if err != nil {
+		return nil, err
+	}
+	messageCount, bodySize := b.headerCounts()
+	data := b.Header.appendBinaryWithCounts(nil, messageCount, bodySize)
+	return append(data, hash.bytes[:]...), nil
+}
+
+// Read in a header summary written by MarshalHeaderSummary
+func UnmarshalHeaderSummary(data []byte) (header *ABlockHeader, hash *Hash, err error) {
+	header = new(ABlockHeader)
+	data, err = header.UnmarshalBinaryData(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) != HASH_LENGTH {
+		return nil, nil, fmt.Errorf("Expected %v bytes of LedgerKeyMR after the header, got %v", HASH_LENGTH, len(data))
+	}
+	hash = new(Hash)
+	copy(hash.bytes[:], data)
+	return header, hash, nil
+}
+
+// Return a 64-bit key of the admin block, cheaper than LedgerKeyMR, to key
+// caches of decoded blocks: the first 8 bytes of the LedgerKeyMR when it is
+// already cached, or else a FNV-1a hash of the binary.  The key of a block
+// therefore changes once its LedgerKeyMR is computed.  It is not for
+// consensus: different blocks can share a key, so a cache hit must still be
+// confirmed by comparing full hashes.  0 is returned if the block cannot be
+// marshalled.
+func (b *AdminBlock) CacheKey() uint64 {
+	b.hashMutex.Lock()
+	fullHash := b.fullHash
+	b.hashMutex.Unlock()
+	if fullHash != nil {
+		return binary.BigEndian.Uint64(fullHash.bytes[:8])
+	}
+
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
+
+// Concatenate the binary of all the entries without any header or framing
+func (b *AdminBlock) Flatten() ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, entry := range b.ABEntries {
+		data, err := entry.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// Admin Block size
+// math.MaxUint64 is returned if the size overflows, use Size to get an error instead.
+func (b *AdminBlock) MarshalledSize() uint64 {
+	size, err := b.Size()
+	if err != nil {
+		return math.MaxUint64
+	}
+	return size
+}
+
+// Admin Block size, failing rather than wrapping around if the sum of the
+// header and entry sizes does not fit in a uint64
+func (b *AdminBlock) Size() (uint64, error) {
+	size := b.Header.MarshalledSize()
+	if size < b.Header.HeaderExpansionSize {
+		return 0, errors.New("Admin block header size overflows uint64")
+	}
+
+	for _, entry := range b.ABEntries {
+		entrySize := entry.MarshalledSize()
+		if size+entrySize < size {
+			return 0, errors.New("Admin block size overflows uint64")
+		}
+		size += entrySize
+	}
+
+	return size, nil
+}
+
+// Maximum serialized admin block size from ActivationHeight on
+type ABlockSizeLimit struct {
+	ActivationHeight uint32
+	MaxBytes         uint64
+}
+
+// Admin block size limits, sorted by activation height
+var ABlockSizeLimits = []ABlockSizeLimit{
+	{ActivationHeight: 0, MaxBytes: MAX_ABLOCK_SIZE},
+}
+
+// Maximum serialized size of an admin block at the directory block height
+func MaxABlockSizeAt(height uint32) uint64 {
+	max := MAX_ABLOCK_SIZE
+	for _, limit := range ABlockSizeLimits {
+		if limit.ActivationHeight > height {
+			break
+		}
+		max = limit.MaxBytes
+	}
+	return max
+}
+
+// Check that the serialized admin block is no larger than maxBytes
+func (b *AdminBlock) ValidateSize(maxBytes uint64) error {
+	size, err := b.Size()
+	if err != nil {
+		return err
+	}
+	if size > maxBytes {
+		return fmt.Errorf("Admin block size %v exceeds the maximum of %v", size, maxBytes)
+	}
+	return nil
+}
+
+// Check the size of the admin block against the limit at its height
+func (b *AdminBlock) ValidateSizeAtHeight() error {
+	return b.ValidateSize(MaxABlockSizeAt(b.Header.DBHeight))
+}
+
+// Size the admin block would have with e added, without adding it
+func (b *AdminBlock) SizeWith(e ABEntry) uint64 {
+	size := b.MarshalledSize()
+	entrySize := e.MarshalledSize()
+	if size+entrySize < size {
+		return math.MaxUint64
+	}
+	return size + entrySize
+}
+
+// Options controlling how an admin block is decoded
+type DecodeOptions struct {
+	// Require the entries to take exactly BodySize bytes and, when decoding
+	// a whole buffer, no bytes to be left after the block.
+	Strict bool
+	// Reject blocks with more entries than this, when positive.
+	MaxEntries int
+	// Keep the remaining body as an UnknownABEntry when an entry type is
+	// neither built in nor registered, instead of failing.  Ignored in
+	// Strict mode.
+	AllowUnknownTypes bool
+	// Skip the checks of BodySize against the data and the entries, for
+	// blocks read back from a local database that validated them on ingest.
+	// The entries are still parsed.
+	TrustedDecode bool
+}
+
+// Options used by UnmarshalBinary and UnmarshalBinaryData
+var DefaultDecodeOptions = DecodeOptions{Strict: true}
+
+// Read in an admin block from data using the given options
+func UnmarshalAdminBlockWithOptions(data []byte, opts DecodeOptions) (*AdminBlock, error) {
+	b := new(AdminBlock)
+	err := b.unmarshalBinary(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Read in only the header of the admin block in data, leaving the entries
+// unparsed.
+func PeekAdminBlockHeader(data []byte) (*ABlockHeader, error) {
+	h := new(ABlockHeader)
+	if _, err := h.UnmarshalBinaryData(data); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (b *AdminBlock) UnmarshalBinaryData(data []byte) (newData []byte, err error) {
+	return b.unmarshalBinaryData(data, DefaultDecodeOptions)
+}
+
+func (b *AdminBlock) unmarshalBinaryData(data []byte, opts DecodeOptions) (newData []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Error unmarshalling: %v", r)
+		}
+	}()
+	b.logMutation("UnmarshalBinaryData")
+	newData = data
+	h := new(ABlockHeader)
+	newData, err = h.UnmarshalBinaryData(newData)
+	if err != nil {
+		return
+	}
+	b.Header = h
+
+	if !opts.TrustedDecode && uint64(b.Header.BodySize) > uint64(len(newData)) {
+		return nil, fmt.Errorf("Invalid BodySize %v for %v remaining bytes", b.Header.BodySize, len(newData))
+	}
+
+	// Every entry takes at least one byte, so a count larger than the
+	// remaining data cannot be valid
+	if uint64(b.Header.MessageCount) > uint64(len(newData)) {
+		return nil, fmt.Errorf("Invalid MessageCount %v for %v remaining bytes", b.Header.MessageCount, len(newData))
+	}
+	if opts.MaxEntries > 0 && uint64(b.Header.MessageCount) > uint64(opts.MaxEntries) {
+		return nil, fmt.Errorf("MessageCount %v exceeds the maximum of %v", b.Header.MessageCount, opts.MaxEntries)
+	}
+
+	body := newData
+	b.ABEntries = make([]ABEntry, 0, b.Header.MessageCount)
+	b.entryTypes = nil
+	for i := uint32(0); i < b.Header.MessageCount; i++ {
+		var entry ABEntry
+		entry, err = newABEntry(newData[0])
+		if err != nil {
+			if opts.Strict || !opts.AllowUnknownTypes {
+				return nil, err
+			}
+			consumed := len(body) - len(newData)
+			if consumed > int(b.Header.BodySize) {
+				return nil, err
+			}
+			unknown := new(UnknownABEntry)
+			unknown.Data = make([]byte, int(b.Header.BodySize)-consumed)
+			copy(unknown.Data, newData)
+			b.ABEntries = append(b.ABEntries, unknown)
+			return newData[len(unknown.Data):], nil
+		}
+		newData, err = entry.UnmarshalBinaryData(newData)
+		if err != nil {
+			return
+		}
+		b.ABEntries = append(b.ABEntries, entry)
+	}
+
+	if opts.Strict && !opts.TrustedDecode && len(body)-len(newData) != int(b.Header.BodySize) {
+		return nil, fmt.Errorf("Entries take %v bytes but BodySize is %v", len(body)-len(newData), b.Header.BodySize)
+	}
+	return
+}
+
+// Read in the binary into the Admin block.
+// The decoded block keeps no reference to data: the header fields,
+// signatures and unknown entries are all copied, so data can be reused or
+// unmapped once the call returns.
+func (b *AdminBlock) UnmarshalBinary(data []byte) (err error) {
+	if ObserveOp != nil {
+		start := time.Now()
+		defer func() { ObserveOp("UnmarshalBinary", len(data), time.Since(start)) }()
+	}
+
+	return b.unmarshalBinary(data, DefaultDecodeOptions)
+}
+
+func (b *AdminBlock) unmarshalBinary(data []byte, opts DecodeOptions) (err error) {
+	rest, err := b.unmarshalBinaryData(data, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Strict && len(rest) != 0 {
+		return fmt.Errorf("%v bytes left after the admin block", len(rest))
+	}
+	return nil
+}
+
+// Factories for admin block entry types registered at runtime
+var abEntryFactories = make(map[byte]func() ABEntry)
+var abEntryFactoriesMutex sync.RWMutex
+
+// Register a factory for an admin block entry type unknown to the decoder.
+// Built-in types and types already registered cannot be registered again.
+func RegisterABEntryType(t byte, factory func() ABEntry) error {
+	if factory == nil {
+		return errors.New("Admin block entry factory cannot be nil")
+	}
+	if _, err := newBuiltinABEntry(t); err == nil {
+		return fmt.Errorf("Admin block entry type %v is built in", t)
+	}
+
+	abEntryFactoriesMutex.Lock()
+	defer abEntryFactoriesMutex.Unlock()
+
+	if _, ok := abEntryFactories[t]; ok {
+		return fmt.Errorf("Admin block entry type %v is already registered", t)
+	}
+	abEntryFactories[t] = factory
+
+	return nil
+}
+
+// Create an empty admin block entry of the given type
+func newABEntry(entryType byte) (ABEntry, error) {
+	if entry, err := newBuiltinABEntry(entryType); err == nil {
+		return entry, nil
+	}
+
+	abEntryFactoriesMutex.RLock()
+	factory, ok := abEntryFactories[entryType]
+	abEntryFactoriesMutex.RUnlock()
+
+	if ok {
+		return factory(), nil
+	}
+	return nil, fmt.Errorf("Unknown admin block entry type %v", entryType)
+}
+
+func newBuiltinABEntry(entryType byte) (ABEntry, error) {
+	switch entryType {
+	case TYPE_DB_SIGNATURE:
+		return new(DBSignatureEntry), nil
+	case TYPE_MINUTE_NUM:
+		return new(EndOfMinuteEntry), nil
+	}
+	return nil, fmt.Errorf("Unknown admin block entry type %v", entryType)
+}
+
+// Read in as much of a damaged admin block as possible.
+// Entries are decoded until the first one that fails; the block holding the
+// good prefix is returned along with the errors encountered.  This is a
+// debugging tool only, consensus code must use UnmarshalBinary.
+func UnmarshalAdminBlockLossy(data []byte) (*AdminBlock, []error) {
+	var errs []error
+
+	b := new(AdminBlock)
+	h := new(ABlockHeader)
+	newData, err := h.UnmarshalBinaryData(data)
+	if err != nil {
+		return nil, append(errs, err)
+	}
+	b.Header = h
+	b.ABEntries = make([]ABEntry, 0)
+
+	for i := uint32(0); i < h.MessageCount; i++ {
+		if len(newData) == 0 {
+			errs = append(errs, fmt.Errorf("Entry %v is missing", i))
+			break
+		}
+		entry, err := newABEntry(newData[0])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Entry %v: %v", i, err))
+			break
+		}
+		newData, err = entry.UnmarshalBinaryData(newData)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Entry %v: %v", i, err))
+			break
+		}
+		b.ABEntries = append(b.ABEntries, entry)
+	}
+
+	if uint32(len(b.ABEntries)) < h.MessageCount {
+		errs = append(errs, fmt.Errorf("Recovered %v of %v entries", len(b.ABEntries), h.MessageCount))
+	}
+
+	return b, errs
+}
+
+// Write out a list of admin block entries to binary: the big-endian
+// uint32 count followed by the entries, each starting with its type byte
+func MarshalABEntries(entries []ABEntry) (data []byte, err error) {
+	data = appendUint32(nil, uint32(len(entries)))
+	for _, entry := range entries {
+		var entryData []byte
+		entryData, err = entry.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, entryData...)
+	}
+	return data, nil
+}
+
+// Read in a list of admin block entries written by MarshalABEntries
+func UnmarshalABEntries(data []byte) (entries []ABEntry, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Error unmarshalling: %v", r)
+		}
+	}()
+	count, newData := binary.BigEndian.Uint32(data[0:4]), data[4:]
+
+	// Every entry takes at least one byte
+	if uint64(count) > uint64(len(newData)) {
+		return nil, fmt.Errorf("Invalid entry count %v for %v remaining bytes", count, len(newData))
+	}
+
+	entries = make([]ABEntry, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var entry ABEntry
+		entry, err = newABEntry(newData[0])
+		if err != nil {
+			return nil, err
+		}
+		newData, err = entry.UnmarshalBinaryData(newData)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(newData) != 0 {
+		return nil, fmt.Errorf("%v bytes left after the entries", len(newData))
+	}
+	return entries, nil
+}
+
+// Return the first DB signature entry of the admin block, or nil
+func (b *AdminBlock) GetDBSignature() ABEntry {
+
+	for _, entry := range b.ABEntries {
+		if entry.Type() == TYPE_DB_SIGNATURE {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// End-of-minute marker position within an admin block
+type MinuteBoundary struct {
+	Minute        byte
+	EntriesBefore int // Entries in the minute, excluding markers
+}
+
+// Return the end-of-minute markers in order, each with the number of
+// entries added during that minute
+func (b *AdminBlock) MinuteBoundaries() []MinuteBoundary {
+	boundaries := make([]MinuteBoundary, 0)
+	count := 0
+	for _, entry := range b.ABEntries {
+		eom, ok := entry.(*EndOfMinuteEntry)
+		if !ok {
+			count++
+			continue
+		}
+		boundaries = append(boundaries, MinuteBoundary{Minute: eom.EOM_Type, EntriesBefore: count})
+		count = 0
+	}
+	return boundaries
+}
+
+// Group the entries of the admin block, other than the end-of-minute markers,
+// by the minute of the marker preceding them.  Entries before the first
+// marker are in minute 0.
+func (b *AdminBlock) GroupByMinute() (map[byte][]ABEntry, error) {
+	groups := make(map[byte][]ABEntry)
+	minute := byte(0)
+	found := false
+	for _, entry := range b.ABEntries {
+		if eom, ok := entry.(*EndOfMinuteEntry); ok {
+			minute = eom.EOM_Type
+			found = true
+			continue
+		}
+		groups[minute] = append(groups[minute], entry)
+	}
+
+	if !found && len(b.ABEntries) > 0 {
+		return nil, ErrNoEOMFound
+	}
+	return groups, nil
+}
+
+// Return the entries added after the last end-of-minute marker of the
+// admin block, or all of them if it has no marker
+func (b *AdminBlock) EntriesSinceLastMinute() []ABEntry {
+	start := 0
+	for i := len(b.ABEntries) - 1; i >= 0; i-- {
+		if _, ok := b.ABEntries[i].(*EndOfMinuteEntry); ok {
+			start = i + 1
+			break
+		}
+	}
+	return append(make([]ABEntry, 0, len(b.ABEntries)-start), b.ABEntries[start:]...)
+}
+
+// Check whether the admin block holds the end-of-minute marker of minute
+func (b *AdminBlock) HasEOMForMinute(minute byte) bool {
+	for _, entry := range b.ABEntries {
+		if eom, ok := entry.(*EndOfMinuteEntry); ok && eom.EOM_Type == minute {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the minutes 1 to 10, in order, that have no end-of-minute marker
+// in the admin block
+func (b *AdminBlock) MissingMinutes() []byte {
+	var seen [MinuteMarker10 + 1]bool
+	for _, entry := range b.ABEntries {
+		if eom, ok := entry.(*EndOfMinuteEntry); ok && eom.EOM_Type >= MinuteMarker1 && eom.EOM_Type <= MinuteMarker10 {
+			seen[eom.EOM_Type] = true
+		}
+	}
+
+	missing := make([]byte, 0)
+	for minute := MinuteMarker1; minute <= MinuteMarker10; minute++ {
+		if !seen[minute] {
+			missing = append(missing, minute)
+		}
+	}
+	return missing
+}
+
+// Check whether the end-of-minute markers of the admin block are strictly increasing
+func (b *AdminBlock) MinutesInOrder() bool {
+	last := -1
+	for _, entry := range b.ABEntries {
+		eom, ok := entry.(*EndOfMinuteEntry)
+		if !ok {
+			continue
+		}
+		if int(eom.EOM_Type) <= last {
+			return false
+		}
+		last = int(eom.EOM_Type)
+	}
+	return true
+}
+
+// Check the structure of the admin block
+func (b *AdminBlock) Validate() error {
+	if b.Header == nil {
+		return errors.New("Admin block has no header")
+	}
+	if !b.MinutesInOrder() {
+		return errors.New("End-of-minute markers are out of order")
+	}
+
+	// DB signatures sign the previous directory block and come first.
+	// Identity entries must reference an identity chain, not a zero hash
+	// or the admin chain itself.
+	minuteSeen := false
+	for _, entry := range b.ABEntries {
+		switch e := entry.(type) {
+		case *EndOfMinuteEntry:
+			minuteSeen = true
+		case *DBSignatureEntry:
+			if minuteSeen {
+				return ErrSignatureOutOfPosition
+			}
+		case IdentityEntry:
+			id := e.IdentityChainID()
+			if id == nil || id.IsSameAs(NewHash()) || id.IsSameAs(b.Header.AdminChainID) {
+				return ErrInvalidIdentity
+			}
+		}
+	}
+	return nil
+}
+
+// Return the entry at index i of the admin block
+func (b *AdminBlock) EntryAt(i int) (ABEntry, error) {
+	if i < 0 || i >= len(b.ABEntries) {
+		return nil, ErrIndexOutOfRange
+	}
+	return b.ABEntries[i], nil
+}
+
+// Return copies of the entries of the admin block, so that callers cannot
+// change the block through them
+func (b *AdminBlock) Entries() ([]ABEntry, error) {
+	entries := make([]ABEntry, 0, len(b.ABEntries))
+	for _, entry := range b.ABEntries {
+		c, err := copyABEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, c)
+	}
+	return entries, nil
+}
+
+// Return a deep copy of the entry by marshalling and unmarshalling it
+func copyABEntry(e ABEntry) (ABEntry, error) {
+	data, err := e.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var c ABEntry
+	if _, ok := e.(*UnknownABEntry); ok {
+		c = new(UnknownABEntry)
+	} else if c, err = newABEntry(e.Type()); err != nil {
+		return nil, err
+	}
+	if err := c.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Replace the entry at index i of the admin block.
+// MessageCount and BodySize are updated and the cached hashes are cleared.
+func (b *AdminBlock) SetEntryAt(i int, entry ABEntry) error {
+	if b.sealed {
+		return ErrBlockSealed
+	}
+	if i < 0 || i >= len(b.ABEntries) {
+		return ErrIndexOutOfRange
+	}
+	if entry == nil {
+		return errors.New("Entry cannot be nil")
+	}
+	b.logMutation("SetEntryAt")
+
+	b.ABEntries[i] = entry
+	b.entryTypes = nil
+	b.updateHeaderCounts()
+	b.fullHash = nil
+	b.partialHash = nil
+	return nil
+}
+
+// Return a deep copy of the admin block.  The cached hashes are not copied
+// and the copy is not sealed.
+func (b *AdminBlock) Clone() (*AdminBlock, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(AdminBlock)
+	if err := c.unmarshalBinary(data, DecodeOptions{AllowUnknownTypes: true}); err != nil {
+		return nil, err
+	}
+	c.Checksum = b.Checksum
+	return c, nil
+}
+
+// Check that the admin block survives a marshal and unmarshal unchanged,
+// reporting the first differing byte offset otherwise.  Meant for tests.
+func (b *AdminBlock) RoundTripTest() error {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	decoded := new(AdminBlock)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		return fmt.Errorf("Round trip unmarshal failed: %v", err)
+	}
+	again, err := decoded.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("Round trip marshal failed: %v", err)
+	}
+
+	for i := 0; i < len(data) && i < len(again); i++ {
+		if data[i] != again[i] {
+			return fmt.Errorf("Round trip differs at byte %v: %02x became %02x", i, data[i], again[i])
+		}
+	}
+	if len(data) != len(again) {
+		return fmt.Errorf("Round trip changed the length from %v to %v bytes", len(data), len(again))
+	}
+	return nil
+}
+
+// Read-only view of an admin block returned by ShallowClone
+type ReadOnlyAdminBlock struct {
+	header    *ABlockHeader
+	abEntries []ABEntry
+}
+
+// Return a read-only copy of the admin block that deep copies the header
+// but shares the entries.  Entries replaced in the admin block afterwards
+// through SetEntryAt or SwapEntries are seen by the copy; entries added are
+// not.
+func (b *AdminBlock) ShallowClone() *ReadOnlyAdminBlock {
+	r := new(ReadOnlyAdminBlock)
+	if b.Header != nil {
+		r.header = b.Header.clone()
+	}
+	r.abEntries = b.ABEntries
+	return r
+}
+
+// Return a copy of the header of the admin block
+func (r *ReadOnlyAdminBlock) Header() *ABlockHeader {
+	if r.header == nil {
+		return nil
+	}
+	return r.header.clone()
+}
+
+func (r *ReadOnlyAdminBlock) EntryCount() int {
+	return len(r.abEntries)
+}
+
+// Return the entry at index i of the admin block
+func (r *ReadOnlyAdminBlock) EntryAt(i int) (ABEntry, error) {
+	if i < 0 || i >= len(r.abEntries) {
+		return nil, ErrIndexOutOfRange
+	}
+	return r.abEntries[i], nil
+}
+
+// Modification of the entries of an admin block applied by Patch.
+// Op is "add" to insert Entry at Index, "remove" to delete the entry at
+// Index or "replace" to overwrite the entry at Index with Entry.
+type PatchOp struct {
+	Op    string
+	Index int
+	Entry ABEntry
+}
+
+// Return a new admin block with the operations applied in order.
+// The admin block itself is never modified, even if an operation fails.
+func (b *AdminBlock) Patch(ops []PatchOp) (*AdminBlock, error) {
+	if b.Header == nil {
+		return nil, errors.New("Admin block has no header")
+	}
+
+	entries := append(make([]ABEntry, 0, len(b.ABEntries)+len(ops)), b.ABEntries...)
+	for i, op := range ops {
+		switch op.Op {
+		case "add":
+			if op.Index < 0 || op.Index > len(entries) {
+				return nil, fmt.Errorf("Op %v: %v", i, ErrIndexOutOfRange)
+			}
+			if op.Entry == nil {
+				return nil, fmt.Errorf("Op %v: entry cannot be nil", i)
+			}
+			entries = append(entries, nil)
+			copy(entries[op.Index+1:], entries[op.Index:])
+			entries[op.Index] = op.Entry
+		case "remove":
+			if op.Index < 0 || op.Index >= len(entries) {
+				return nil, fmt.Errorf("Op %v: %v", i, ErrIndexOutOfRange)
+			}
+			entries = append(entries[:op.Index], entries[op.Index+1:]...)
+		case "replace":
+			if op.Index < 0 || op.Index >= len(entries) {
+				return nil, fmt.Errorf("Op %v: %v", i, ErrIndexOutOfRange)
+			}
+			if op.Entry == nil {
+				return nil, fmt.Errorf("Op %v: entry cannot be nil", i)
+			}
+			entries[op.Index] = op.Entry
+		default:
+			return nil, fmt.Errorf("Op %v: unknown operation %q", i, op.Op)
+		}
+	}
+
+	p := new(AdminBlock)
+	header := *b.Header
+	p.Header = &header
+	p.ABEntries = entries
+	p.updateHeaderCounts()
+	return p, nil
+}
+
+// Split the admin block into one block with the entries before index i and
+// one with the entries from index i on.  The split must fall on a minute
+// boundary, right after an end-of-minute marker or at either end of the block.
+// The first block keeps the header of b and the second follows it in the chain.
+func (b *AdminBlock) SplitAt(i int) (*AdminBlock, *AdminBlock, error) {
+	if i < 0 || i > len(b.ABEntries) {
+		return nil, nil, ErrIndexOutOfRange
+	}
+	if i > 0 && i < len(b.ABEntries) {
+		if _, ok := b.ABEntries[i-1].(*EndOfMinuteEntry); !ok {
+			return nil, nil, ErrNotSplittableAtEOM
+		}
+	}
+
+	first := new(AdminBlock)
+	header := *b.Header
+	first.Header = &header
+	first.ABEntries = append(make([]ABEntry, 0, i), b.ABEntries[:i]...)
+	first.updateHeaderCounts()
+
+	second := new(AdminBlock)
+	header2 := *b.Header
+	second.Header = &header2
+	second.ABEntries = append(make([]ABEntry, 0, len(b.ABEntries)-i), b.ABEntries[i:]...)
+	second.updateHeaderCounts()
+	if err := second.SetPrevHashFrom(first); err != nil {
+		return nil, nil, err
+	}
+
+	return first, second, nil
+}
+
+// Return a copy of the admin block without nil entries, with MessageCount and
+// BodySize recalculated.  The admin block itself is left unchanged.
+func (b *AdminBlock) Compact() (*AdminBlock, error) {
+	if b.Header == nil {
+		return nil, errors.New("Admin block has no header")
+	}
+
+	c := new(AdminBlock)
+	header := *b.Header
+	c.Header = &header
+	c.ABEntries = make([]ABEntry, 0, len(b.ABEntries))
+	for _, entry := range b.ABEntries {
+		if entry != nil {
+			c.ABEntries = append(c.ABEntries, entry)
+		}
+	}
+	c.updateHeaderCounts()
+
+	return c, nil
+}
+
+// Check whether the admin block can be pruned once the chain is checkpointed
+// at checkpointHeight: it must be below the checkpoint and hold nothing but
+// end-of-minute markers, since any other entry has a lasting effect.
+func (b *AdminBlock) IsPrunable(checkpointHeight uint32) bool {
+	if b.Header == nil || b.Header.DBHeight >= checkpointHeight {
+		return false
+	}
+	for _, entry := range b.ABEntries {
+		if _, ok := entry.(*EndOfMinuteEntry); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Swap the entries at indices i and j of the admin block.
+// The cached hashes are cleared since they depend on the order.
+func (b *AdminBlock) SwapEntries(i, j int) error {
+	if b.sealed {
+		return ErrBlockSealed
+	}
+	if i < 0 || i >= len(b.ABEntries) || j < 0 || j >= len(b.ABEntries) {
+		return ErrIndexOutOfRange
+	}
+	b.logMutation("SwapEntries")
+
+	b.ABEntries[i], b.ABEntries[j] = b.ABEntries[j], b.ABEntries[i]
+	b.fullHash = nil
+	b.partialHash = nil
+	return nil
+}
+
+// Put the entries of the admin block in canonical order: the DB signatures
+// first, ordered by identity, then the other entries in their current order.
+// The cached hashes are cleared since they depend on the order.
+func (b *AdminBlock) SortEntries() error {
+	if b.sealed {
+		return ErrBlockSealed
+	}
+	b.logMutation("SortEntries")
+
+	sort.Stable(byCanonicalOrder(b.ABEntries))
+	b.fullHash = nil
+	b.partialHash = nil
+	return nil
+}
+
+// Sort admin block entries with the DB signatures first, by identity
+type byCanonicalOrder []ABEntry
+
+func (s byCanonicalOrder) Len() int      { return len(s) }
+func (s byCanonicalOrder) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byCanonicalOrder) Less(i, j int) bool {
+	si, iok := s[i].(*DBSignatureEntry)
+	sj, jok := s[j].(*DBSignatureEntry)
+	if !iok || !jok {
+		return iok && !jok
+	}
+	return bytes.Compare(identityBytes(si), identityBytes(sj)) < 0
+}
+
+func identityBytes(e *DBSignatureEntry) []byte {
+	if e.IdentityAdminChainID == nil {
+		return nil
+	}
+	return e.IdentityAdminChainID.bytes[:]
+}
+
+// Sort the entries, rebuild the header counts and return the canonical
+// serialization of the admin block.  The admin block itself is reordered.
+// A block decoded from the network and canonicalized may not serialize to
+// the bytes received if the sender was not canonical, which is itself a
+// reason to distrust the block.
+func (b *AdminBlock) Canonicalize() ([]byte, error) {
+	if b.Header == nil {
+		return nil, errors.New("Admin block has no header")
+	}
+	if err := b.SortEntries(); err != nil {
+		return nil, err
+	}
+	b.updateHeaderCounts()
+	return b.MarshalBinary()
+}
+
+// Return the entry types found in the admin block, sorted and without
+// duplicates.  The result is cached until an entry is added or replaced
+// through the AdminBlock methods.
+func (b *AdminBlock) EntryTypes() []byte {
+	if b.entryTypes == nil {
+		var seen [256]bool
+		for _, entry := range b.ABEntries {
+			seen[entry.Type()] = true
+		}
+		b.entryTypes = make([]byte, 0)
+		for t := range seen {
+			if seen[t] {
+				b.entryTypes = append(b.entryTypes, byte(t))
+			}
+		}
+	}
+	return append([]byte(nil), b.entryTypes...)
+}
+
+// Return the highest entry type in the admin block, and false if the block
+// has no entries.  A type above the highest one known locally means the
+// block comes from a newer protocol version.
+func (b *AdminBlock) MaxEntryType() (byte, bool) {
+	types := b.EntryTypes()
+	if len(types) == 0 {
+		return 0, false
+	}
+	return types[len(types)-1], true
+}
+
+// Return the first entry of type t in the admin block and its index, and
+// false if there is none
+func (b *AdminBlock) FirstEntryOfType(t byte) (ABEntry, int, bool) {
+	for i, entry := range b.ABEntries {
+		if entry.Type() == t {
+			return entry, i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// Return the last entry of type t in the admin block and its index, and
+// false if there is none
+func (b *AdminBlock) LastEntryOfType(t byte) (ABEntry, int, bool) {
+	for i := len(b.ABEntries) - 1; i >= 0; i-- {
+		if b.ABEntries[i].Type() == t {
+			return b.ABEntries[i], i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// Return the entries of the admin block satisfying the predicate
+func (b *AdminBlock) FilterEntries(pred func(ABEntry) bool) []ABEntry {
+	entries := make([]ABEntry, 0)
+	for _, entry := range b.ABEntries {
+		if pred(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Output scheduled by a coinbase descriptor
+type CoinbaseOutput struct {
+	Address *Hash
+	Amount  uint64
+}
+
+// Print the output with its address as a human readable "FA" address
+func (o CoinbaseOutput) String() string {
+	return fmt.Sprintf("%v %v", FormatFactoidAddress(o.Address), o.Amount)
+}
+
+// Encode the output with its address as a human readable "FA" address
+func (o CoinbaseOutput) MarshalJSON() ([]byte, error) {
+	return EncodeJSON(struct {
+		Address string
+		Amount  uint64
+	}{FormatFactoidAddress(o.Address), o.Amount})
+}
+
+// Admin block entry scheduling coinbase outputs.  No built in entry type
+// implements it yet; registered entry types can.
+type CoinbaseDescriptor interface {
+	ABEntry
+	Outputs() []CoinbaseOutput
+}
+
+// Return the coinbase outputs scheduled by the admin block, in entry order,
+// or an empty slice if the block has no coinbase descriptor
+func (b *AdminBlock) CoinbaseOutputs() []CoinbaseOutput {
+	outputs := make([]CoinbaseOutput, 0)
+	for _, entry := range b.ABEntries {
+		if descriptor, ok := entry.(CoinbaseDescriptor); ok {
+			outputs = append(outputs, descriptor.Outputs()...)
+		}
+	}
+	return outputs
+}
+
+// Admin block with in-memory notes keyed by entry index, for tooling.
+// The annotations are never serialized.
+type AnnotatedAdminBlock struct {
+	*AdminBlock
+	Annotations map[int]string
+}
+
+// Attach annotations to the entries of the admin block
+func (b *AdminBlock) Annotate(annotations map[int]string) *AnnotatedAdminBlock {
+	if annotations == nil {
+		annotations = make(map[int]string)
+	}
+	return &AnnotatedAdminBlock{AdminBlock: b, Annotations: annotations}
+}
+
+// Template of the diagnostic HTML table of the admin block entries
+const adminBlockTableTmplText = `<table class="adminblock">
+	<tr>
+		<th>Index</th>
+		<th>Type</th>
+		<th>Identity</th>
+		<th>Public Key</th>
+	</tr>
+	{{range .}}<tr>
+		<td>{{.Index}}</td>
+		<td>{{.Type}}</td>
+		<td>{{.Identity}}</td>
+		<td>{{.PubKey}}</td>
+	</tr>
+	{{end}}
+</table>
+`
+
+var adminBlockTableTmpl = template.Must(template.New("adminblock_table").Parse(adminBlockTableTmplText))
+
+// Check whether two admin blocks are identical by comparing their headers
+// and entries.  The cached hashes are derived data and are ignored, so a
+// block that has been hashed equals an identical one that has not.  The
+// header MessageCount and BodySize are compared as they are marshalled,
+// from the entries.
+func (b *AdminBlock) IsEqual(other *AdminBlock) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+	if b.Header == nil || other.Header == nil {
+		return b.Header == other.Header
+	}
+
+	h1, h2 := *b.Header, *other.Header
+	h1.MessageCount, h1.BodySize = b.headerCounts()
+	h2.MessageCount, h2.BodySize = other.headerCounts()
+	if !h1.IsEqual(&h2) {
+		return false
+	}
+
+	if len(b.ABEntries) != len(other.ABEntries) {
+		return false
+	}
+	for i := range b.ABEntries {
+		e1, err := b.ABEntries[i].MarshalBinary()
+		if err != nil {
+			return false
+		}
+		e2, err := other.ABEntries[i].MarshalBinary()
+		if err != nil {
+			return false
+		}
+		if !bytes.Equal(e1, e2) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Error returned when an identity signed an admin block more than once
+type ErrDuplicateIdentity struct {
+	ChainID *Hash
+}
+
+func (e ErrDuplicateIdentity) Error() string {
+	return fmt.Sprintf("Duplicate signature from identity %v", e.ChainID.String())
+}
+
+// Check whether the admin block holds more than one DB signature from the
+// same identity.  A repeated identity and public key pair is a special case
+// of this and is reported the same way.  A DB signature without an identity
+// is reported as ErrNilIdentity.
+func (b *AdminBlock) HasConflict() (bool, error) {
+	seen := make(map[[HASH_LENGTH]byte]bool)
+	for _, entry := range b.ABEntries {
+		dbSig, ok := entry.(*DBSignatureEntry)
+		if !ok {
+			continue
+		}
+		if dbSig.IdentityAdminChainID == nil {
+			return false, ErrNilIdentity
 		}
+		if seen[dbSig.IdentityAdminChainID.bytes] {
+			return true, ErrDuplicateIdentity{ChainID: dbSig.IdentityAdminChainID}
+		}
+		seen[dbSig.IdentityAdminChainID.bytes] = true
 	}
-	return ab.partialHash, nil
+	return false, nil
 }
 
-// Create an empty Admin Block
-func CreateAdminBlock(chain *AdminChain, prev *AdminBlock, cap uint) (b *AdminBlock, err error) {
-	if prev == nil && chain.NextBlockHeight != 0 {
-		return nil, errors.New("Previous block cannot be nil")
-	} else if prev != nil && chain.NextBlockHeight == 0 {
-		return nil, errors.New("Origin block cannot have a parent block")
+// Tracks the entries with a lasting effect across admin blocks, to catch
+// the same entry appearing in more than one block
+type EntryDedupTracker struct {
+	firstSeen map[[HASH_LENGTH]byte]uint32
+	mutex     sync.Mutex
+}
+
+func NewEntryDedupTracker() *EntryDedupTracker {
+	t := new(EntryDedupTracker)
+	t.firstSeen = make(map[[HASH_LENGTH]byte]uint32)
+	return t
+}
+
+// Record e as appearing at height.  Returns true and the height it first
+// appeared at when the same entry was seen before.  End-of-minute markers
+// repeat in every block and are never reported.
+func (t *EntryDedupTracker) Seen(e ABEntry, height uint32) (bool, uint32) {
+	if _, ok := e.(*EndOfMinuteEntry); ok {
+		return false, height
 	}
+	data, err := e.MarshalBinary()
+	if err != nil {
+		return false, height
+	}
+	key := Sha(data).bytes
 
-	b = new(AdminBlock)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 
-	b.Header = new(ABlockHeader)
-	b.Header.AdminChainID = chain.ChainID
+	if first, ok := t.firstSeen[key]; ok {
+		return true, first
+	}
+	t.firstSeen[key] = height
+	return false, height
+}
 
-	if prev == nil {
-		b.Header.PrevLedgerKeyMR = NewHash()
-	} else {
-		b.Header.PrevLedgerKeyMR, err = prev.LedgerKeyMR()
-		if err != nil {
-			return
+// Check which federated servers signed the admin block.  hasQuorum is true
+// when at least threshold of federated signed, and missing lists those that
+// did not.  Signatures without an identity sign for no server, and a nil
+// identity in federated is always missing.
+func (b *AdminBlock) HasFederatedSignatures(federated []*Hash, threshold int) (hasQuorum bool, missing []*Hash) {
+	signed := make(map[[HASH_LENGTH]byte]bool)
+	for _, entry := range b.ABEntries {
+		if dbSig, ok := entry.(*DBSignatureEntry); ok && dbSig.IdentityAdminChainID != nil {
+			signed[dbSig.IdentityAdminChainID.bytes] = true
 		}
 	}
 
-	b.Header.DBHeight = chain.NextBlockHeight
-	b.ABEntries = make([]ABEntry, 0, cap)
+	missing = make([]*Hash, 0)
+	for _, identity := range federated {
+		if identity == nil || !signed[identity.bytes] {
+			missing = append(missing, identity)
+		}
+	}
 
-	return b, err
+	return len(federated)-len(missing) >= threshold, missing
 }
 
-// Build the SHA512Half hash for the admin block
-func (b *AdminBlock) buildFullBHash() (err error) {
-	var binaryAB []byte
-	binaryAB, err = b.MarshalBinary()
-	if err != nil {
-		return
+// Error returned when an admin block does not hold the expected number of
+// DB signatures
+type ErrSignatureCountMismatch struct {
+	Expected int
+	Got      int
+}
+
+func (e ErrSignatureCountMismatch) Error() string {
+	return fmt.Sprintf("Expected %v DB signatures, got %v", e.Expected, e.Got)
+}
+
+// Check that the admin block holds exactly one DB signature per authority
+func (b *AdminBlock) VerifySignatureCount(expected int) error {
+	got := 0
+	for _, entry := range b.ABEntries {
+		if _, ok := entry.(*DBSignatureEntry); ok {
+			got++
+		}
 	}
-	b.fullHash = Sha512Half(binaryAB)
-	return
+	if got != expected {
+		return ErrSignatureCountMismatch{Expected: expected, Got: got}
+	}
+	return nil
 }
 
-// Build the SHA256 hash for the admin block
-func (b *AdminBlock) buildPartialHash() (err error) {
-	var binaryAB []byte
-	binaryAB, err = b.MarshalBinary()
-	if err != nil {
-		return
+// Sum the weights of the identities that signed the admin block, keyed by
+// the hex IdentityAdminChainID.  Each identity is counted once.  Signers
+// missing from weights count for nothing, or fail the call when strict.
+// Signatures without an identity are skipped, or fail with ErrNilIdentity
+// when strict.
+func (b *AdminBlock) ComputeSignerWeight(weights map[string]int, strict bool) (int, error) {
+	total := 0
+	seen := make(map[[HASH_LENGTH]byte]bool)
+	for _, entry := range b.ABEntries {
+		dbSig, ok := entry.(*DBSignatureEntry)
+		if !ok {
+			continue
+		}
+		if dbSig.IdentityAdminChainID == nil {
+			if strict {
+				return 0, ErrNilIdentity
+			}
+			continue
+		}
+		if seen[dbSig.IdentityAdminChainID.bytes] {
+			continue
+		}
+		seen[dbSig.IdentityAdminChainID.bytes] = true
+
+		weight, ok := weights[dbSig.IdentityAdminChainID.String()]
+		if !ok && strict {
+			return 0, fmt.Errorf("No weight for identity %v", dbSig.IdentityAdminChainID.String())
+		}
+		total += weight
 	}
-	b.partialHash = Sha(binaryAB)
-	return
+	return total, nil
 }
 
-// Add an Admin Block entry to the block
-func (b *AdminBlock) AddABEntry(e ABEntry) (err error) {
-	b.ABEntries = append(b.ABEntries, e)
-	return
+// Summary of an admin block for the RPC interfaces
+type AdminBlockInfo struct {
+	AdminChainID    string
+	PrevLedgerKeyMR string
+	DBHeight        uint32
+	MessageCount    uint32
+	BodySize        uint32
+	LedgerKeyMR     string
+
+	// Number of entries keyed by entry type name
+	EntryTypeCounts map[string]int
 }
 
-// Add the end-of-minute marker into the admin block
-func (b *AdminBlock) AddEndOfMinuteMarker(eomType byte) (err error) {
-	eOMEntry := &EndOfMinuteEntry{
-		entryType: TYPE_MINUTE_NUM,
-		EOM_Type:  eomType}
+var _ Printable = (*AdminBlockInfo)(nil)
+
+// Summarize the admin block.  MessageCount and BodySize are those of the
+// binary, as for LedgerKeyMR.
+func (b *AdminBlock) Info() *AdminBlockInfo {
+	i := new(AdminBlockInfo)
+	i.AdminChainID = b.Header.AdminChainID.String()
+	i.PrevLedgerKeyMR = b.Header.PrevLedgerKeyMR.String()
+	i.DBHeight = b.Header.DBHeight
+	if lkmr, err := b.LedgerKeyMR(); err == nil {
+		i.LedgerKeyMR = lkmr.String()
+	}
+	i.MessageCount, i.BodySize = b.headerCounts()
 
-	b.AddABEntry(eOMEntry)
+	i.EntryTypeCounts = make(map[string]int)
+	for _, entry := range b.ABEntries {
+		i.EntryTypeCounts[ABEntryTypeName(entry.Type())]++
+	}
 
-	return
+	return i
 }
 
-// Write out the AdminBlock to binary.
-func (b *AdminBlock) MarshalBinary() (data []byte, err error) {
-	var buf bytes.Buffer
+func (e *AdminBlockInfo) JSONByte() ([]byte, error) {
+	return EncodeJSON(e)
+}
 
-	data, _ = b.Header.MarshalBinary()
-	buf.Write(data)
+func (e *AdminBlockInfo) JSONString() (string, error) {
+	return EncodeJSONString(e)
+}
 
-	for i := uint32(0); i < b.Header.MessageCount; i++ {
-		data, _ := b.ABEntries[i].MarshalBinary()
-		buf.Write(data)
-	}
-	return buf.Bytes(), err
+func (e *AdminBlockInfo) JSONBuffer(b *bytes.Buffer) error {
+	return EncodeJSONToBuffer(e, b)
 }
 
-// Admin Block size
-func (b *AdminBlock) MarshalledSize() uint64 {
-	var size uint64 = 0
+func (e *AdminBlockInfo) Spew() string {
+	return Spew(e)
+}
 
-	size += b.Header.MarshalledSize()
+// Return the admin block as nested maps for GraphQL resolvers, with camelCase
+// field names and nil for missing values.  Each entry carries its concrete
+// type in __typename so that it resolves as a member of a union.
+func (b *AdminBlock) ToGraphQLMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"adminChainId":    nil,
+		"prevLedgerKeyMR": nil,
+		"dbHeight":        nil,
+		"messageCount":    nil,
+		"bodySize":        nil,
+		"ledgerKeyMR":     nil,
+	}
+	if b.Header != nil {
+		m["adminChainId"] = graphQLHash(b.Header.AdminChainID)
+		m["prevLedgerKeyMR"] = graphQLHash(b.Header.PrevLedgerKeyMR)
+		m["dbHeight"] = b.Header.DBHeight
+		if lkmr, err := b.LedgerKeyMR(); err == nil {
+			m["ledgerKeyMR"] = lkmr.String()
+		}
+		m["messageCount"], m["bodySize"] = b.headerCounts()
+	}
 
+	entries := make([]interface{}, 0, len(b.ABEntries))
 	for _, entry := range b.ABEntries {
-		size += entry.MarshalledSize()
+		switch e := entry.(type) {
+		case *DBSignatureEntry:
+			var pubKey, sig interface{}
+			if e.PubKey.Key != nil {
+				pubKey = e.PubKey.String()
+			}
+			if e.PrevDBSig != nil {
+				sig = hex.EncodeToString(e.PrevDBSig[:])
+			}
+			entries = append(entries, map[string]interface{}{
+				"__typename":           "DBSignatureEntry",
+				"identityAdminChainId": graphQLHash(e.IdentityAdminChainID),
+				"pubKey":               pubKey,
+				"prevDBSig":            sig,
+			})
+		case *EndOfMinuteEntry:
+			entries = append(entries, map[string]interface{}{
+				"__typename": "EndOfMinuteEntry",
+				"minute":     e.EOM_Type,
+			})
+		default:
+			data, _ := entry.MarshalBinary()
+			entries = append(entries, map[string]interface{}{
+				"__typename": "UnknownABEntry",
+				"entryType":  entry.Type(),
+				"data":       hex.EncodeToString(data),
+			})
+		}
 	}
+	m["entries"] = entries
 
-	return size
+	return m
 }
 
-func (b *AdminBlock) UnmarshalBinaryData(data []byte) (newData []byte, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("Error unmarshalling: %v", r)
+func graphQLHash(h *Hash) interface{} {
+	if h == nil {
+		return nil
+	}
+	return h.String()
+}
+
+// One admin block entry as a line of ToJSONLines
+type AdminBlockJSONLine struct {
+	AdminChainID string
+	DBHeight     uint32
+	Index        int
+	Type         string
+	Data         string // Hex encoded binary of the entry
+}
+
+// Write out the entries of the admin block as JSON lines, one object per
+// entry carrying the chain id and height of the block for correlation
+func (b *AdminBlock) ToJSONLines() (string, error) {
+	var buf bytes.Buffer
+	for i, entry := range b.ABEntries {
+		data, err := entry.MarshalBinary()
+		if err != nil {
+			return "", err
 		}
-	}()
-	newData = data
-	h := new(ABlockHeader)
-	newData, err = h.UnmarshalBinaryData(newData)
-	if err != nil {
-		return
+		line := AdminBlockJSONLine{
+			AdminChainID: b.Header.AdminChainID.String(),
+			DBHeight:     b.Header.DBHeight,
+			Index:        i,
+			Type:         ABEntryTypeName(entry.Type()),
+			Data:         hex.EncodeToString(data)}
+		if err := EncodeJSONToBuffer(line, &buf); err != nil {
+			return "", err
+		}
+		buf.WriteByte('\n')
 	}
-	b.Header = h
+	return buf.String(), nil
+}
 
-	b.ABEntries = make([]ABEntry, b.Header.MessageCount)
-	for i := uint32(0); i < b.Header.MessageCount; i++ {
-		if newData[0] == TYPE_DB_SIGNATURE {
-			b.ABEntries[i] = new(DBSignatureEntry)
-		} else if newData[0] == TYPE_MINUTE_NUM {
-			b.ABEntries[i] = new(EndOfMinuteEntry)
+// Rebuild an admin block from the output of ToJSONLines.  Only the chain id
+// and height of the header are carried by the lines, so PrevLedgerKeyMR is
+// left zero.
+func AdminBlockFromJSONLines(lines string) (*AdminBlock, error) {
+	b := new(AdminBlock)
+	b.Header = new(ABlockHeader)
+	b.Header.PrevLedgerKeyMR = NewHash()
+	b.ABEntries = make([]ABEntry, 0)
+
+	for n, text := range strings.Split(lines, "\n") {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		line := new(AdminBlockJSONLine)
+		if err := DecodeJSONString(text, line); err != nil {
+			return nil, fmt.Errorf("Line %v: %v", n+1, err)
 		}
-		newData, err = b.ABEntries[i].UnmarshalBinaryData(newData)
+		if line.Index != len(b.ABEntries) {
+			return nil, fmt.Errorf("Line %v: expected entry %v, got %v", n+1, len(b.ABEntries), line.Index)
+		}
+
+		chainID, err := HexToHash(line.AdminChainID)
 		if err != nil {
-			return
+			return nil, fmt.Errorf("Line %v: %v", n+1, err)
+		}
+		if len(b.ABEntries) == 0 {
+			b.Header.AdminChainID = chainID
+			b.Header.DBHeight = line.DBHeight
+		} else if !chainID.IsSameAs(b.Header.AdminChainID) || line.DBHeight != b.Header.DBHeight {
+			return nil, fmt.Errorf("Line %v belongs to another block", n+1)
+		}
+
+		data, err := hex.DecodeString(line.Data)
+		if err != nil || len(data) == 0 {
+			return nil, fmt.Errorf("Line %v: invalid entry data", n+1)
+		}
+		entry, err := newABEntry(data[0])
+		if err != nil {
+			return nil, fmt.Errorf("Line %v: %v", n+1, err)
 		}
+		if err := entry.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("Line %v: %v", n+1, err)
+		}
+		b.ABEntries = append(b.ABEntries, entry)
 	}
-	return
+
+	if len(b.ABEntries) == 0 {
+		return nil, errors.New("No entries in the JSON lines")
+	}
+	b.updateHeaderCounts()
+	return b, nil
 }
 
-// Read in the binary into the Admin block.
-func (b *AdminBlock) UnmarshalBinary(data []byte) (err error) {
-	_, err = b.UnmarshalBinaryData(data)
-	return
+// Describe the effect of each entry of the admin block, one line per entry
+func (b *AdminBlock) AuditLog() ([]string, error) {
+	lines := make([]string, 0, len(b.ABEntries))
+	for i, entry := range b.ABEntries {
+		switch e := entry.(type) {
+		case *DBSignatureEntry:
+			lines = append(lines, fmt.Sprintf("Authority %v submitted directory block signature with key %v at height %v",
+				e.IdentityAdminChainID.String(), e.PubKey.String(), b.Header.DBHeight))
+		case *EndOfMinuteEntry:
+			lines = append(lines, fmt.Sprintf("End of minute %v at height %v", e.EOM_Type, b.Header.DBHeight))
+		default:
+			if entry == nil || !entry.IsInterpretable() {
+				return nil, fmt.Errorf("No audit description for entry %v", i)
+			}
+			lines = append(lines, fmt.Sprintf("%v at height %v", entry.Interpret(), b.Header.DBHeight))
+		}
+	}
+	return lines, nil
 }
 
-// Read in the binary into the Admin block.
-func (b *AdminBlock) GetDBSignature() ABEntry {
+// Render the entries of the admin block as an HTML table
+func (b *AdminBlock) ToHTMLTable() (string, error) {
+	type row struct {
+		Index    int
+		Type     string
+		Identity string
+		PubKey   string
+	}
 
-	for i := uint32(0); i < b.Header.MessageCount; i++ {
-		if b.ABEntries[i].Type() == TYPE_DB_SIGNATURE {
-			return b.ABEntries[i]
+	rows := make([]row, len(b.ABEntries))
+	for i, entry := range b.ABEntries {
+		rows[i].Index = i
+		rows[i].Type = ABEntryTypeName(entry.Type())
+		if dbSig, ok := entry.(*DBSignatureEntry); ok {
+			rows[i].Identity = dbSig.IdentityAdminChainID.String()
+			rows[i].PubKey = dbSig.PubKey.String()
 		}
 	}
 
-	return nil
+	var buf bytes.Buffer
+	if err := adminBlockTableTmpl.Execute(&buf, rows); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Serialize the admin block in the named format: "binary", "json" or
+// "cbor".  There is no YAML encoder among the dependencies of this tree, so
+// "yaml" is an error, as is any other format.
+func (b *AdminBlock) SerializeTo(format string) ([]byte, error) {
+	switch format {
+	case "binary":
+		return b.MarshalBinary()
+	case "json":
+		return b.JSONByte()
+	case "cbor":
+		return b.MarshalCBOR()
+	}
+	return nil, fmt.Errorf("Unsupported admin block format %q", format)
 }
 
 func (e *AdminBlock) JSONByte() ([]byte, error) {
@@ -235,31 +2433,72 @@ type ABlockHeader struct {
 var _ Printable = (*ABlockHeader)(nil)
 var _ BinaryMarshallable = (*ABlockHeader)(nil)
 
-// Write out the ABlockHeader to binary.
-func (b *ABlockHeader) MarshalBinary() (data []byte, err error) {
-	var buf bytes.Buffer
+// Check whether two admin block headers are identical, comparing every
+// field including MessageCount and BodySize
+func (b *ABlockHeader) IsEqual(other *ABlockHeader) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+	return sameHash(b.AdminChainID, other.AdminChainID) &&
+		sameHash(b.PrevLedgerKeyMR, other.PrevLedgerKeyMR) &&
+		b.DBHeight == other.DBHeight &&
+		b.HeaderExpansionSize == other.HeaderExpansionSize &&
+		bytes.Equal(b.HeaderExpansionArea, other.HeaderExpansionArea) &&
+		b.MessageCount == other.MessageCount &&
+		b.BodySize == other.BodySize
+}
 
-	data, err = b.AdminChainID.MarshalBinary()
-	if err != nil {
-		return nil, err
+// Return a deep copy of the header
+func (b *ABlockHeader) clone() *ABlockHeader {
+	h := *b
+	h.AdminChainID = copyHash(b.AdminChainID)
+	h.PrevLedgerKeyMR = copyHash(b.PrevLedgerKeyMR)
+	if b.HeaderExpansionArea != nil {
+		h.HeaderExpansionArea = append([]byte(nil), b.HeaderExpansionArea...)
 	}
-	buf.Write(data)
+	return &h
+}
 
-	data, err = b.PrevLedgerKeyMR.MarshalBinary()
-	if err != nil {
-		return nil, err
+func copyHash(h *Hash) *Hash {
+	if h == nil {
+		return nil
+	}
+	c := *h
+	return &c
+}
+
+// Compare two hashes, treating two nil hashes as equal
+func sameHash(a, b *Hash) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
-	buf.Write(data)
+	return a.IsSameAs(b)
+}
+
+// Write out the ABlockHeader to binary.
+func (b *ABlockHeader) MarshalBinary() (data []byte, err error) {
+	return b.appendBinaryWithCounts(nil, b.MessageCount, b.BodySize), nil
+}
+
+// Append the binary of the ABlockHeader to dst using the given counts in
+// place of the MessageCount and BodySize fields.
+func (b *ABlockHeader) appendBinaryWithCounts(dst []byte, messageCount uint32, bodySize uint32) []byte {
+	dst = append(dst, b.AdminChainID.bytes[:]...)
+	dst = append(dst, b.PrevLedgerKeyMR.bytes[:]...)
+
+	dst = appendUint32(dst, b.DBHeight)
 
-	binary.Write(&buf, binary.BigEndian, b.DBHeight)
+	dst = AppendVarInt(dst, b.HeaderExpansionSize)
+	dst = append(dst, b.HeaderExpansionArea...)
 
-	EncodeVarInt(&buf, b.HeaderExpansionSize)
-	buf.Write(b.HeaderExpansionArea)
+	dst = appendUint32(dst, messageCount)
+	dst = appendUint32(dst, bodySize)
 
-	binary.Write(&buf, binary.BigEndian, b.MessageCount)
-	binary.Write(&buf, binary.BigEndian, b.BodySize)
+	return dst
+}
 
-	return buf.Bytes(), err
+func appendUint32(dst []byte, v uint32) []byte {
+	return append(dst, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
 }
 
 func (b *ABlockHeader) MarshalledSize() uint64 {
@@ -298,7 +2537,12 @@ func (b *ABlockHeader) UnmarshalBinaryData(data []byte) (newData []byte, err err
 	b.DBHeight, newData = binary.BigEndian.Uint32(newData[0:4]), newData[4:]
 
 	b.HeaderExpansionSize, newData = DecodeVarInt(newData)
-	b.HeaderExpansionArea, newData = newData[:b.HeaderExpansionSize], newData[b.HeaderExpansionSize:]
+	if b.HeaderExpansionSize > uint64(len(newData)) {
+		return nil, fmt.Errorf("Invalid HeaderExpansionSize %v for %v remaining bytes", b.HeaderExpansionSize, len(newData))
+	}
+	b.HeaderExpansionArea = make([]byte, b.HeaderExpansionSize)
+	copy(b.HeaderExpansionArea, newData)
+	newData = newData[b.HeaderExpansionSize:]
 
 	b.MessageCount, newData = binary.BigEndian.Uint32(newData[0:4]), newData[4:]
 	b.BodySize, newData = binary.BigEndian.Uint32(newData[0:4]), newData[4:]
@@ -328,6 +2572,31 @@ func (e *ABlockHeader) Spew() string {
 	return Spew(e)
 }
 
+// Human readable name of an admin block entry type
+func ABEntryTypeName(t byte) string {
+	switch t {
+	case TYPE_MINUTE_NUM:
+		return "EndOfMinute"
+	case TYPE_DB_SIGNATURE:
+		return "DBSignature"
+	case TYPE_REVEAL_MATRYOSHKA:
+		return "RevealMatryoshka"
+	case TYPE_ADD_MATRYOSHKA:
+		return "AddMatryoshka"
+	case TYPE_ADD_SERVER_COUNT:
+		return "AddServerCount"
+	case TYPE_ADD_FED_SERVER:
+		return "AddFedServer"
+	case TYPE_REMOVE_FED_SERVER:
+		return "RemoveFedServer"
+	case TYPE_ADD_FED_SERVER_KEY:
+		return "AddFedServerKey"
+	case TYPE_ADD_BTC_ANCHOR_KEY:
+		return "AddBTCAnchorKey"
+	}
+	return fmt.Sprintf("Unknown(%v)", t)
+}
+
 // Generic admin block entry type
 type ABEntry interface {
 	Printable
@@ -338,6 +2607,15 @@ type ABEntry interface {
 	Hash() *Hash
 }
 
+// Admin block entry referencing an identity chain, such as the server add,
+// remove and promote entries.  DB signature entries are not identity
+// entries: a node without an identity signs with the zero hash.
+type IdentityEntry interface {
+	ABEntry
+
+	IdentityChainID() *Hash
+}
+
 type Sig [64]byte
 
 func (s *Sig) MarshalText() ([]byte, error) {
@@ -364,13 +2642,17 @@ type DBSignatureEntry struct {
 var _ ABEntry = (*DBSignatureEntry)(nil)
 var _ BinaryMarshallable = (*DBSignatureEntry)(nil)
 
-// Create a new DB Signature Entry
+// Create a new DB Signature Entry.  The entry holds its own copy of the
+// signature bytes.
 func NewDBSignatureEntry(identityAdminChainID *Hash, sig Signature) (e *DBSignatureEntry) {
 	e = new(DBSignatureEntry)
 	e.entryType = TYPE_DB_SIGNATURE
 	e.IdentityAdminChainID = identityAdminChainID
 	e.PubKey = sig.Pub
-	e.PrevDBSig = (*Sig)(sig.Sig)
+	if sig.Sig != nil {
+		e.PrevDBSig = new(Sig)
+		copy(e.PrevDBSig[:], sig.Sig[:])
+	}
 	return
 }
 
@@ -378,28 +2660,78 @@ func (e *DBSignatureEntry) Type() byte {
 	return e.entryType
 }
 
-func (e *DBSignatureEntry) MarshalBinary() (data []byte, err error) {
-	var buf bytes.Buffer
-
-	buf.Write([]byte{e.entryType})
+// Check that the public key of the entry is the key registered for its
+// identity at height
+func (e *DBSignatureEntry) ValidateKeyBinding(reg *IdentityRegistry, height uint32) error {
+	if reg == nil {
+		return errors.New("Identity registry cannot be nil")
+	}
+	if e.PubKey.Key == nil {
+		return errors.New("Entry has no public key")
+	}
 
-	data, err = e.IdentityAdminChainID.MarshalBinary()
+	key, err := reg.KeyAt(e.IdentityAdminChainID, height)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("Identity %v at height %v: %v", e.IdentityAdminChainID.String(), height, err)
+	}
+	if subtle.ConstantTimeCompare(key.Key[:], e.PubKey.Key[:]) != 1 {
+		return fmt.Errorf("Key %v is not the key %v of identity %v at height %v",
+			e.PubKey.String(), key.String(), e.IdentityAdminChainID.String(), height)
 	}
-	buf.Write(data)
+	return nil
+}
 
-	_, err = buf.Write(e.PubKey.Key[:])
+// Check the signature of the binary of the previous directory block header.
+// When registry is not nil the signature is checked against the key the
+// identity had at height, otherwise against the key held by the entry.
+func (e *DBSignatureEntry) VerifySignature(prevDBHeader *DBlockHeader, registry *IdentityRegistry, height uint32) (bool, error) {
+	if e.PrevDBSig == nil {
+		return false, errors.New("Entry has no signature")
+	}
+	if prevDBHeader == nil {
+		return false, errors.New("Previous directory block header cannot be nil")
+	}
+	headerBytes, err := prevDBHeader.MarshalBinary()
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	_, err = buf.Write(e.PrevDBSig[:])
-	if err != nil {
-		return nil, err
+	key := &e.PubKey
+	if registry != nil {
+		key, err = registry.KeyAt(e.IdentityAdminChainID, height)
+		if err != nil {
+			return false, err
+		}
+	}
+	if key.Key == nil {
+		return false, errors.New("Entry has no public key")
 	}
 
-	return buf.Bytes(), nil
+	return key.Verify(headerBytes, (*[64]byte)(e.PrevDBSig)), nil
+}
+
+// Overwrite the signature of the entry with zeros and drop it.  The entry
+// cannot be marshalled or verified afterwards: MarshalBinary returns an
+// error, and so do the block hashes of a block holding it, while Hash
+// returns nil.  The entry holds no private key material.
+func (e *DBSignatureEntry) Wipe() {
+	if e.PrevDBSig != nil {
+		*e.PrevDBSig = Sig{}
+		e.PrevDBSig = nil
+	}
+}
+
+func (e *DBSignatureEntry) MarshalBinary() (data []byte, err error) {
+	if e.PrevDBSig == nil {
+		return nil, errors.New("DB signature entry has no signature")
+	}
+
+	data = make([]byte, 0, e.MarshalledSize())
+	data = append(data, e.entryType)
+	data = append(data, e.IdentityAdminChainID.bytes[:]...)
+	data = append(data, e.PubKey.Key[:]...)
+	data = append(data, e.PrevDBSig[:]...)
+	return data, nil
 }
 
 func (e *DBSignatureEntry) MarshalledSize() uint64 {
@@ -468,7 +2800,11 @@ func (e *DBSignatureEntry) Interpret() string {
 	return ""
 }
 
+// Hash of the binary of the entry, or nil for a wiped entry
 func (e *DBSignatureEntry) Hash() *Hash {
+	if e.PrevDBSig == nil {
+		return nil
+	}
 	bin, err := e.MarshalBinary()
 	if err != nil {
 		panic(err)
@@ -476,6 +2812,21 @@ func (e *DBSignatureEntry) Hash() *Hash {
 	return Sha(bin)
 }
 
+// Minutes of the end-of-minute markers, as found in EOM_Type.
+// Minute 0 is not valid.
+const (
+	MinuteMarker1 byte = iota + 1
+	MinuteMarker2
+	MinuteMarker3
+	MinuteMarker4
+	MinuteMarker5
+	MinuteMarker6
+	MinuteMarker7
+	MinuteMarker8
+	MinuteMarker9
+	MinuteMarker10
+)
+
 type EndOfMinuteEntry struct {
 	entryType byte
 	EOM_Type  byte
@@ -490,13 +2841,7 @@ func (m *EndOfMinuteEntry) Type() byte {
 }
 
 func (e *EndOfMinuteEntry) MarshalBinary() (data []byte, err error) {
-	var buf bytes.Buffer
-
-	buf.Write([]byte{e.entryType})
-
-	buf.Write([]byte{e.EOM_Type})
-
-	return buf.Bytes(), nil
+	return []byte{e.entryType, e.EOM_Type}, nil
 }
 
 func (e *EndOfMinuteEntry) MarshalledSize() uint64 {
@@ -517,6 +2862,9 @@ func (e *EndOfMinuteEntry) UnmarshalBinaryData(data []byte) (newData []byte, err
 
 	e.entryType, newData = newData[0], newData[1:]
 	e.EOM_Type, newData = newData[0], newData[1:]
+	if e.EOM_Type == 0 {
+		return nil, ErrInvalidMinute
+	}
 
 	return
 }
@@ -557,3 +2905,65 @@ func (e *EndOfMinuteEntry) Hash() *Hash {
 	}
 	return Sha(bin)
 }
+
+// Opaque admin block entry holding the rest of a block body that starts with
+// an entry type the decoder does not know.  Only produced by lenient decoding.
+type UnknownABEntry struct {
+	Data []byte
+}
+
+var _ ABEntry = (*UnknownABEntry)(nil)
+
+func (e *UnknownABEntry) Type() byte {
+	if len(e.Data) == 0 {
+		return 0
+	}
+	return e.Data[0]
+}
+
+func (e *UnknownABEntry) MarshalBinary() (data []byte, err error) {
+	return e.Data, nil
+}
+
+func (e *UnknownABEntry) MarshalledSize() uint64 {
+	return uint64(len(e.Data))
+}
+
+func (e *UnknownABEntry) UnmarshalBinaryData(data []byte) (newData []byte, err error) {
+	e.Data = make([]byte, len(data))
+	copy(e.Data, data)
+	return nil, nil
+}
+
+func (e *UnknownABEntry) UnmarshalBinary(data []byte) (err error) {
+	_, err = e.UnmarshalBinaryData(data)
+	return
+}
+
+func (e *UnknownABEntry) JSONByte() ([]byte, error) {
+	return EncodeJSON(e)
+}
+
+func (e *UnknownABEntry) JSONString() (string, error) {
+	return EncodeJSONString(e)
+}
+
+func (e *UnknownABEntry) JSONBuffer(b *bytes.Buffer) error {
+	return EncodeJSONToBuffer(e, b)
+}
+
+func (e *UnknownABEntry) Spew() string {
+	return Spew(e)
+}
+
+func (e *UnknownABEntry) IsInterpretable() bool {
+	return false
+}
+
+func (e *UnknownABEntry) Interpret() string {
+	return ""
+}
+
+func (e *UnknownABEntry) Hash() *Hash {
+	return Sha(e.Data)
+}
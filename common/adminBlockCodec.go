@@ -0,0 +1,90 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"github.com/ugorji/go/codec"
+)
+
+var cborHandle codec.CborHandle
+
+// Layout of an admin block for the codec encodings, MessagePack and CBOR.
+// The entries are kept in their binary form, as written by
+// MarshalABEntries, since ABEntry is an interface.
+type AdminBlockMsg struct {
+	AdminChainID        []byte
+	PrevLedgerKeyMR     []byte
+	DBHeight            uint32
+	HeaderExpansionArea []byte
+	Entries             []byte
+}
+
+// Fill in the codec layout of the admin block
+func NewAdminBlockMsg(b *AdminBlock) (msg *AdminBlockMsg, err error) {
+	msg = new(AdminBlockMsg)
+	msg.AdminChainID = b.Header.AdminChainID.Bytes()
+	msg.PrevLedgerKeyMR = b.Header.PrevLedgerKeyMR.Bytes()
+	msg.DBHeight = b.Header.DBHeight
+	msg.HeaderExpansionArea = b.Header.HeaderExpansionArea
+	msg.Entries, err = MarshalABEntries(b.ABEntries)
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Rebuild the admin block from its codec layout.  MessageCount and
+// BodySize are computed from the entries.
+func (msg *AdminBlockMsg) AdminBlock() (*AdminBlock, error) {
+	var err error
+	b := new(AdminBlock)
+	b.Header = new(ABlockHeader)
+	b.Header.AdminChainID, err = NewShaHash(msg.AdminChainID)
+	if err != nil {
+		return nil, err
+	}
+	b.Header.PrevLedgerKeyMR, err = NewShaHash(msg.PrevLedgerKeyMR)
+	if err != nil {
+		return nil, err
+	}
+	b.Header.DBHeight = msg.DBHeight
+	b.Header.HeaderExpansionSize = uint64(len(msg.HeaderExpansionArea))
+	b.Header.HeaderExpansionArea = msg.HeaderExpansionArea
+
+	b.ABEntries, err = UnmarshalABEntries(msg.Entries)
+	if err != nil {
+		return nil, err
+	}
+	b.updateHeaderCounts()
+
+	return b, nil
+}
+
+// Serialize the admin block to CBOR
+func (b *AdminBlock) MarshalCBOR() (data []byte, err error) {
+	msg, err := NewAdminBlockMsg(b)
+	if err != nil {
+		return nil, err
+	}
+	err = codec.NewEncoderBytes(&data, &cborHandle).Encode(msg)
+	return data, err
+}
+
+// Read in an admin block serialized by MarshalCBOR
+func (b *AdminBlock) UnmarshalCBOR(data []byte) error {
+	msg := new(AdminBlockMsg)
+	if err := codec.NewDecoderBytes(data, &cborHandle).Decode(msg); err != nil {
+		return err
+	}
+	decoded, err := msg.AdminBlock()
+	if err != nil {
+		return err
+	}
+	b.Header = decoded.Header
+	b.ABEntries = decoded.ABEntries
+	b.fullHash = nil
+	b.partialHash = nil
+	return nil
+}
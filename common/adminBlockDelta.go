@@ -0,0 +1,123 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Tags of the entries in a delta encoded admin block
+const (
+	deltaEntryRef     byte = iota // Index of an identical entry in the base block
+	deltaEntryLiteral             // Full binary of the entry
+)
+
+// Encode the admin block relative to prev.
+// The delta holds the full header followed by the entries, where entries
+// with the same binary as an entry of prev are replaced by its index.
+func (b *AdminBlock) DeltaEncode(prev *AdminBlock) ([]byte, error) {
+	if prev == nil {
+		return nil, errors.New("Previous block cannot be nil")
+	}
+
+	prevIndex := make(map[[HASH_LENGTH]byte]int)
+	for i, entry := range prev.ABEntries {
+		data, err := entry.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		h := Sha(data).bytes
+		if _, ok := prevIndex[h]; !ok {
+			prevIndex[h] = i
+		}
+	}
+
+	messageCount, bodySize := b.headerCounts()
+	delta := b.Header.appendBinaryWithCounts(nil, messageCount, bodySize)
+
+	for _, entry := range b.ABEntries {
+		data, err := entry.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := prevIndex[Sha(data).bytes]; ok {
+			delta = append(delta, deltaEntryRef)
+			delta = AppendVarInt(delta, uint64(i))
+		} else {
+			delta = append(delta, deltaEntryLiteral)
+			delta = append(delta, data...)
+		}
+	}
+
+	return delta, nil
+}
+
+// Rebuild an admin block from base and a delta produced by DeltaEncode
+func AdminBlockFromDelta(base *AdminBlock, delta []byte) (b *AdminBlock, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Error decoding delta: %v", r)
+		}
+	}()
+	if base == nil {
+		return nil, errors.New("Base block cannot be nil")
+	}
+
+	b = new(AdminBlock)
+	b.Header = new(ABlockHeader)
+	newData, err := b.Header.UnmarshalBinaryData(delta)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(b.Header.MessageCount) > uint64(len(newData)) {
+		return nil, fmt.Errorf("Invalid MessageCount %v for %v remaining bytes", b.Header.MessageCount, len(newData))
+	}
+
+	b.ABEntries = make([]ABEntry, 0, b.Header.MessageCount)
+	for i := uint32(0); i < b.Header.MessageCount; i++ {
+		tag := newData[0]
+		newData = newData[1:]
+
+		switch tag {
+		case deltaEntryRef:
+			var index uint64
+			index, newData = DecodeVarInt(newData)
+			if index >= uint64(len(base.ABEntries)) {
+				return nil, fmt.Errorf("Entry %v refers to missing base entry %v", i, index)
+			}
+			data, err := base.ABEntries[index].MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			entry, err := newABEntry(data[0])
+			if err != nil {
+				return nil, err
+			}
+			if err = entry.UnmarshalBinary(data); err != nil {
+				return nil, err
+			}
+			b.ABEntries = append(b.ABEntries, entry)
+		case deltaEntryLiteral:
+			entry, err := newABEntry(newData[0])
+			if err != nil {
+				return nil, err
+			}
+			newData, err = entry.UnmarshalBinaryData(newData)
+			if err != nil {
+				return nil, err
+			}
+			b.ABEntries = append(b.ABEntries, entry)
+		default:
+			return nil, fmt.Errorf("Unknown delta entry tag %v", tag)
+		}
+	}
+
+	if len(newData) != 0 {
+		return nil, fmt.Errorf("%v bytes left after the delta", len(newData))
+	}
+
+	return b, nil
+}
@@ -0,0 +1,50 @@
+package common_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+func TestAdminBlockDeltaRoundTrip(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockDeltaRoundTrip\n---\n")
+
+	prev := newTestAdminBlock(t)
+	sig := UnmarshalBinarySignature(bytes.Repeat([]byte{0x11}, 96))
+	prev.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	prev.AddEndOfMinuteMarker(1)
+	prev.AddEndOfMinuteMarker(2)
+
+	block := newTestAdminBlock(t)
+	block.Header.DBHeight = 1
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("two")), sig))
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+
+	delta, err := block.DeltaEncode(prev)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	full, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(delta) >= len(full) {
+		t.Errorf("Delta of %v bytes is not smaller than the %v byte block", len(delta), len(full))
+	}
+
+	block2, err := AdminBlockFromDelta(prev, delta)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	full2, err := block2.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(full, full2) {
+		t.Errorf("Round trip mismatch: %X vs %X", full, full2)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build go1.18
+// +build go1.18
+
+package common_test
+
+import (
+	"testing"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+func FuzzAdminBlockUnmarshal(f *testing.F) {
+	block := new(AdminBlock)
+	block.Header = new(ABlockHeader)
+	block.Header.AdminChainID = NewHash()
+	block.Header.AdminChainID.SetBytes(ADMIN_CHAINID)
+	block.Header.PrevLedgerKeyMR = Sha([]byte("previous admin block"))
+	block.Header.DBHeight = 42
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	block.AddABEntry(NewDBSignatureEntry(NewHash(), sig))
+	block.AddEndOfMinuteMarker(1)
+
+	data, err := block.MarshalBinary()
+	if err != nil {
+		f.Fatalf("%v", err)
+	}
+	f.Add(data)
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		block := new(AdminBlock)
+		block.UnmarshalBinary(data)
+	})
+}
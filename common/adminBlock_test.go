@@ -0,0 +1,2554 @@
+package common_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+func TestAddDBSignature(t *testing.T) {
+	fmt.Printf("\n---\nTestAddDBSignature\n---\n")
+
+	priv := new(PrivateKey)
+	if err := priv.GenerateKey(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	block := newTestAdminBlock(t)
+
+	identity := NewHash()
+	prevDBHeader := newTestDBlockHeader()
+	if err := block.AddDBSignature(identity, priv.Key[:], prevDBHeader); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(block.ABEntries) != 1 {
+		t.Fatalf("Expected 1 entry, got %v", len(block.ABEntries))
+	}
+
+	dbSig := block.ABEntries[0].(*DBSignatureEntry)
+	if dbSig.PubKey.String() != priv.Pub.String() {
+		t.Error("Public key was not derived from the private key")
+	}
+	// The node signs the binary of the header, see SignDirectoryBlock
+	headerBytes, err := prevDBHeader.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if *dbSig.PrevDBSig != Sig(*priv.Sign(headerBytes).Sig) {
+		t.Error("Signature is not over the previous directory block header binary")
+	}
+	if ok, err := dbSig.VerifySignature(prevDBHeader, nil, 0); !ok || err != nil {
+		t.Errorf("Signature does not verify: %v", err)
+	}
+
+	if err := block.AddDBSignature(identity, priv.Key[:10], prevDBHeader); err == nil {
+		t.Error("Expected an error for a short private key")
+	}
+	if err := block.AddDBSignature(identity, priv.Key[:], nil); err == nil {
+		t.Error("Expected an error for a nil header")
+	}
+}
+
+func newTestDBlockHeader() *DBlockHeader {
+	header := new(DBlockHeader)
+	header.BodyMR = Sha([]byte("body"))
+	header.PrevKeyMR = Sha([]byte("previous key mr"))
+	header.PrevLedgerKeyMR = Sha([]byte("previous ledger key mr"))
+	header.DBHeight = 7
+	return header
+}
+
+func TestAdminBlockSigningDigest(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSigningDigest\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+
+	// SHA256 of the empty header with MessageCount 1 and BodySize 2,
+	// followed by the minute 1 marker
+	want := "635e72528f4432efb8ca10571a0744e9e44204f2ce7891ed939a333988ae253d"
+	digest, err := block.SigningDigest()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if digest.String() != want {
+		t.Errorf("Signing digest is %v, want %v", digest, want)
+	}
+
+	// A DB signature entry does not change the digest
+	priv := new(PrivateKey)
+	if err := priv.GenerateKey(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	signed := newTestAdminBlock(t)
+	if err := signed.AddDBSignature(NewHash(), priv.Key[:], newTestDBlockHeader()); err != nil {
+		t.Fatalf("%v", err)
+	}
+	signed.AddEndOfMinuteMarker(1)
+	digest, err = signed.SigningDigest()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if digest.String() != want {
+		t.Errorf("Signing digest changed to %v with a DB signature", digest)
+	}
+
+	// Any other entry does
+	signed.AddEndOfMinuteMarker(2)
+	digest, err = signed.SigningDigest()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if digest.String() == want {
+		t.Error("Expected an end-of-minute marker to change the signing digest")
+	}
+}
+
+func TestAdminBlockMarshalEntryCount(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMarshalEntryCount\n---\n")
+
+	block := newTestAdminBlock(t)
+	for i := byte(1); i <= 3; i++ {
+		block.AddEndOfMinuteMarker(i)
+	}
+	if block.Header.MessageCount != 3 {
+		t.Errorf("Expected AddABEntry to keep MessageCount current, got %v", block.Header.MessageCount)
+	}
+	// The binary carries the live count even when the header is stale
+	block.Header.MessageCount = 0
+
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block.Header.MessageCount != 0 {
+		t.Errorf("Expected marshalling to leave MessageCount alone, got %v", block.Header.MessageCount)
+	}
+
+	block2 := new(AdminBlock)
+	if err := block2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(block2.ABEntries) != 3 {
+		t.Errorf("Expected 3 entries, got %v", len(block2.ABEntries))
+	}
+}
+
+func newTestAdminBlock(t *testing.T) *AdminBlock {
+	chain := new(AdminChain)
+	chain.ChainID = NewHash()
+	chain.ChainID.SetBytes(ADMIN_CHAINID)
+	block, err := CreateAdminBlock(chain, nil, 10)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return block
+}
+
+func TestEmptyAdminBlockMarshal(t *testing.T) {
+	fmt.Printf("\n---\nTestEmptyAdminBlockMarshal\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.Header.MessageCount = 7
+	block.Header.BodySize = 11
+
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	golden := "000000000000000000000000000000000000000000000000000000000000000a" + // AdminChainID
+		"0000000000000000000000000000000000000000000000000000000000000000" + // PrevLedgerKeyMR
+		"00000000" + // DBHeight
+		"00" + // HeaderExpansionSize
+		"00000000" + // MessageCount
+		"00000000" // BodySize
+	if hex.EncodeToString(data) != golden {
+		t.Errorf("Empty admin block marshalled to %x, want %s", data, golden)
+	}
+}
+
+func TestEmptyAdminBlockUnmarshal(t *testing.T) {
+	fmt.Printf("\n---\nTestEmptyAdminBlockUnmarshal\n---\n")
+
+	data, err := newTestAdminBlock(t).MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	block := new(AdminBlock)
+	if err := block.UnmarshalBinary(data); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block.Header.MessageCount != 0 || len(block.ABEntries) != 0 {
+		t.Errorf("Expected no entries, got MessageCount %v and %v entries", block.Header.MessageCount, len(block.ABEntries))
+	}
+
+	data2, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(data, data2) {
+		t.Errorf("Round trip of an empty block changed %x into %x", data, data2)
+	}
+}
+
+func TestUnmarshalAdminBlockLossy(t *testing.T) {
+	fmt.Printf("\n---\nTestUnmarshalAdminBlockLossy\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+	block.AddABEntry(NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(make([]byte, 96))))
+
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	block2, errs := UnmarshalAdminBlockLossy(data[:len(data)-1])
+	if block2 == nil {
+		t.Fatalf("Expected a partial block, got %v", errs)
+	}
+	if len(errs) == 0 {
+		t.Error("Expected errors for a truncated block")
+	}
+	if len(block2.ABEntries) != 2 {
+		t.Errorf("Expected 2 recovered entries, got %v", len(block2.ABEntries))
+	}
+
+	block3, errs := UnmarshalAdminBlockLossy(data)
+	if len(errs) != 0 {
+		t.Errorf("Unexpected errors %v", errs)
+	}
+	if len(block3.ABEntries) != 3 {
+		t.Errorf("Expected 3 entries, got %v", len(block3.ABEntries))
+	}
+}
+
+func TestAdminBlockSizeOverflow(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSizeOverflow\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	if _, err := block.Size(); err != nil {
+		t.Errorf("%v", err)
+	}
+
+	block.Header.HeaderExpansionSize = math.MaxUint64 - 10
+	if _, err := block.Size(); err == nil {
+		t.Error("Expected an overflow error")
+	}
+	if block.MarshalledSize() != math.MaxUint64 {
+		t.Error("Expected MarshalledSize to saturate on overflow")
+	}
+}
+
+func TestAdminChainExportRange(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminChainExportRange\n---\n")
+
+	chain := new(AdminChain)
+	chain.ChainID = NewHash()
+	chain.ChainID.SetBytes(ADMIN_CHAINID)
+
+	var prev *AdminBlock
+	for i := 0; i < 4; i++ {
+		block, err := CreateAdminBlock(chain, prev, 5)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		chain.AddABlockToAChain(block)
+		chain.NextBlockHeight++
+		prev = block
+	}
+
+	blocks, err := chain.ExportRange(1, 3)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(blocks) != 3 || blocks[0].Header.DBHeight != 1 || blocks[2].Header.DBHeight != 3 {
+		t.Errorf("Unexpected range %v", blocks)
+	}
+
+	if _, err := chain.ExportRange(2, 4); err != ErrBlockNotFound {
+		t.Errorf("Expected ErrBlockNotFound, got %v", err)
+	}
+}
+
+func TestRegisterABEntryType(t *testing.T) {
+	fmt.Printf("\n---\nTestRegisterABEntryType\n---\n")
+
+	if err := RegisterABEntryType(TYPE_DB_SIGNATURE, func() ABEntry { return new(EndOfMinuteEntry) }); err == nil {
+		t.Error("Expected an error registering a built-in type")
+	}
+
+	// A custom type is decoded through the registered factory
+	if err := RegisterABEntryType(0xf0, func() ABEntry { return new(EndOfMinuteEntry) }); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := RegisterABEntryType(0xf0, func() ABEntry { return new(EndOfMinuteEntry) }); err == nil {
+		t.Error("Expected an error registering a type twice")
+	}
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	data[len(data)-2] = 0xf0
+
+	block2 := new(AdminBlock)
+	if err := block2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block2.ABEntries[0].Type() != 0xf0 {
+		t.Errorf("Expected entry type 0xf0, got %v", block2.ABEntries[0].Type())
+	}
+}
+
+func TestAdminBlockUnmarshalHugeBodySize(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockUnmarshalHugeBodySize\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// BodySize is the last field of the header, right before the entry
+	binary.BigEndian.PutUint32(data[len(data)-6:], 0xffffffff)
+
+	block2 := new(AdminBlock)
+	if err := block2.UnmarshalBinary(data); err == nil {
+		t.Error("Expected an error for a BodySize larger than the data")
+	}
+}
+
+func TestAdminBlockMutationLog(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMutationLog\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+
+	log := block.EnableMutationLog()
+	block.AddEndOfMinuteMarker(2)
+	if _, err := block.LedgerKeyMR(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if len(log.Events) != 2 {
+		t.Fatalf("Expected 2 events, got %v", len(log.Events))
+	}
+	if log.Events[0].Op != "AddABEntry" || log.Events[1].Op != "buildFullBHash" {
+		t.Errorf("Unexpected events %v", log.Events)
+	}
+	if len(log.Events[0].Callers) == 0 {
+		t.Error("Expected caller information")
+	}
+}
+
+func TestAdminBlockToHTMLTable(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockToHTMLTable\n---\n")
+
+	block := newTestAdminBlock(t)
+	sig := UnmarshalBinarySignature(bytes.Repeat([]byte{0xab}, 96))
+	block.AddABEntry(NewDBSignatureEntry(NewHash(), sig))
+	block.AddEndOfMinuteMarker(1)
+
+	table, err := block.ToHTMLTable()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !strings.Contains(table, "DBSignature") || !strings.Contains(table, "EndOfMinute") {
+		t.Errorf("Missing entry types in %s", table)
+	}
+	if !strings.Contains(table, sig.Pub.String()) {
+		t.Errorf("Missing public key in %s", table)
+	}
+}
+
+func TestAdminBlockAnnotate(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockAnnotate\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	annotated := block.Annotate(map[int]string{1: "late signature"})
+	if annotated.AdminBlock != block || annotated.Annotations[1] != "late signature" {
+		t.Errorf("Unexpected annotated block %v", annotated.Annotations)
+	}
+	// The annotations are not part of the binary
+	annotatedData, err := annotated.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(annotatedData, data) {
+		t.Error("Expected annotations to leave the binary unchanged")
+	}
+
+	if empty := block.Annotate(nil); empty.Annotations == nil {
+		t.Error("Expected an empty annotation map for nil annotations")
+	}
+}
+
+func TestAdminBlockAuditLog(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockAuditLog\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.Header.DBHeight = 5
+	block.AddABEntry(NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(bytes.Repeat([]byte{0xab}, 96))))
+	block.AddEndOfMinuteMarker(1)
+
+	lines, err := block.AuditLog()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	want := []string{
+		"Authority " + strings.Repeat("00", 32) + " submitted directory block signature with key " +
+			strings.Repeat("ab", 32) + " at height 5",
+		"End of minute 1 at height 5",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %v lines, got %v", len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Line %v is %q, want %q", i, lines[i], want[i])
+		}
+	}
+
+	block.AddABEntry(&UnknownABEntry{Data: []byte{0x42}})
+	if _, err := block.AuditLog(); err == nil {
+		t.Error("Expected an error for an entry without an audit description")
+	}
+}
+
+func TestAdminBlockInfo(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockInfo\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.Header.DBHeight = 5
+	block.AddABEntry(NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(bytes.Repeat([]byte{0xab}, 96))))
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+
+	info := block.Info()
+	lkmr, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if info.AdminChainID != hex.EncodeToString(ADMIN_CHAINID) || info.PrevLedgerKeyMR != strings.Repeat("00", 32) {
+		t.Errorf("Unexpected chain %v and previous LedgerKeyMR %v", info.AdminChainID, info.PrevLedgerKeyMR)
+	}
+	if info.DBHeight != 5 || info.LedgerKeyMR != lkmr.String() {
+		t.Errorf("Unexpected height %v and LedgerKeyMR %v", info.DBHeight, info.LedgerKeyMR)
+	}
+	// One 129 byte DB signature and two 2 byte markers
+	if info.MessageCount != 3 || info.BodySize != 133 {
+		t.Errorf("Expected MessageCount 3 and BodySize 133, got %v and %v", info.MessageCount, info.BodySize)
+	}
+	if info.EntryTypeCounts["DBSignature"] != 1 || info.EntryTypeCounts["EndOfMinute"] != 2 || len(info.EntryTypeCounts) != 2 {
+		t.Errorf("Unexpected entry type counts %v", info.EntryTypeCounts)
+	}
+}
+
+func BenchmarkAdminBlockMarshalBinary(b *testing.B) {
+	block := createBenchmarkAdminBlock()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		block.MarshalBinary()
+	}
+}
+
+func BenchmarkAdminBlockMarshalBinaryAppend(b *testing.B) {
+	block := createBenchmarkAdminBlock()
+	dst := make([]byte, 0, block.MarshalledSize())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst, _ = block.MarshalBinaryAppend(dst[:0])
+	}
+}
+
+func BenchmarkAdminBlockDecode(b *testing.B) {
+	benchmarkAdminBlockDecode(b, DefaultDecodeOptions)
+}
+
+func BenchmarkAdminBlockDecodeTrusted(b *testing.B) {
+	benchmarkAdminBlockDecode(b, DecodeOptions{Strict: true, TrustedDecode: true})
+}
+
+func benchmarkAdminBlockDecode(b *testing.B, opts DecodeOptions) {
+	block := createBenchmarkAdminBlock()
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	for i := 0; i < 1000; i++ {
+		block.AddABEntry(NewDBSignatureEntry(NewHash(), sig))
+	}
+	data, err := block.MarshalBinary()
+	if err != nil {
+		b.Fatalf("%v", err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := UnmarshalAdminBlockWithOptions(data, opts); err != nil {
+			b.Fatalf("%v", err)
+		}
+	}
+}
+
+func createBenchmarkAdminBlock() *AdminBlock {
+	block := new(AdminBlock)
+	block.Header = new(ABlockHeader)
+	block.Header.AdminChainID = NewHash()
+	block.Header.AdminChainID.SetBytes(ADMIN_CHAINID)
+	block.Header.PrevLedgerKeyMR = NewHash()
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	for i := 0; i < 10; i++ {
+		block.AddABEntry(NewDBSignatureEntry(NewHash(), sig))
+		block.AddEndOfMinuteMarker(byte(i + 1))
+	}
+	return block
+}
+
+func TestAdminBlockHasConflict(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockHasConflict\n---\n")
+
+	block := newTestAdminBlock(t)
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("two")), sig))
+
+	if conflict, err := block.HasConflict(); conflict || err != nil {
+		t.Errorf("Unexpected conflict %v", err)
+	}
+
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	conflict, err := block.HasConflict()
+	if !conflict {
+		t.Error("Expected a conflict")
+	}
+	if dup, ok := err.(ErrDuplicateIdentity); !ok || !dup.ChainID.IsSameAs(Sha([]byte("one"))) {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	anonymous := newTestAdminBlock(t)
+	anonymous.AddABEntry(NewDBSignatureEntry(nil, sig))
+	if conflict, err := anonymous.HasConflict(); conflict || err != ErrNilIdentity {
+		t.Errorf("Expected ErrNilIdentity, got %v %v", conflict, err)
+	}
+}
+
+func TestAdminBlockIsEqual(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockIsEqual\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	block2 := new(AdminBlock)
+	if err := block2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !block.IsEqual(block2) {
+		t.Error("Expected decoded block to equal the original")
+	}
+
+	// Both hashes cached
+	block.LedgerKeyMR()
+	block2.LedgerKeyMR()
+	if !block.IsEqual(block2) {
+		t.Error("Expected blocks with the same hash to be equal")
+	}
+
+	block3 := newTestAdminBlock(t)
+	block3.AddEndOfMinuteMarker(2)
+	if block.IsEqual(block3) {
+		t.Error("Expected blocks with different entries to differ")
+	}
+}
+
+func TestAdminBlockSeal(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSeal\n---\n")
+
+	block := newTestAdminBlock(t)
+	if err := block.AddEndOfMinuteMarker(1); err != nil {
+		t.Fatalf("%v", err)
+	}
+	hash, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	block.Seal()
+	if err := block.AddEndOfMinuteMarker(2); err != ErrBlockSealed {
+		t.Errorf("Expected ErrBlockSealed, got %v", err)
+	}
+	if err := block.AddABEntry(NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(make([]byte, 96)))); err != ErrBlockSealed {
+		t.Errorf("Expected ErrBlockSealed, got %v", err)
+	}
+
+	hash2, err := block.LedgerKeyMR()
+	if err != nil || !hash.IsSameAs(hash2) {
+		t.Errorf("Hash changed after sealing: %v", err)
+	}
+}
+
+func TestUnmarshalAdminBlockWithOptions(t *testing.T) {
+	fmt.Printf("\n---\nTestUnmarshalAdminBlockWithOptions\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// Trailing bytes
+	padded := append(append([]byte{}, data...), 0x00)
+	if err := new(AdminBlock).UnmarshalBinary(padded); err == nil {
+		t.Error("Expected strict decoding to reject trailing bytes")
+	}
+	if _, err := UnmarshalAdminBlockWithOptions(padded, DecodeOptions{}); err != nil {
+		t.Errorf("Lenient decoding failed: %v", err)
+	}
+
+	// Too many entries
+	if _, err := UnmarshalAdminBlockWithOptions(data, DecodeOptions{MaxEntries: 1}); err == nil {
+		t.Error("Expected MaxEntries to be enforced")
+	}
+
+	// Unknown entry type
+	unknown := append([]byte{}, data...)
+	unknown[len(unknown)-2] = 0xee
+	if _, err := UnmarshalAdminBlockWithOptions(unknown, DecodeOptions{Strict: true, AllowUnknownTypes: true}); err == nil {
+		t.Error("Expected strict decoding to reject unknown types")
+	}
+	block2, err := UnmarshalAdminBlockWithOptions(unknown, DecodeOptions{AllowUnknownTypes: true})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(block2.ABEntries) != 2 || block2.ABEntries[1].Type() != 0xee {
+		t.Errorf("Unexpected entries %v", block2.ABEntries)
+	}
+}
+
+func TestAdminBlockMinutesInOrder(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMinutesInOrder\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(3)
+	block.AddEndOfMinuteMarker(5)
+	if !block.MinutesInOrder() {
+		t.Error("Expected minutes to be in order")
+	}
+	if err := block.Validate(); err != nil {
+		t.Errorf("%v", err)
+	}
+
+	block.AddEndOfMinuteMarker(3)
+	if block.MinutesInOrder() {
+		t.Error("Expected minute 3 after minute 5 to be out of order")
+	}
+	if err := block.Validate(); err == nil {
+		t.Error("Expected Validate to reject out of order minutes")
+	}
+
+	repeated := newTestAdminBlock(t)
+	repeated.AddEndOfMinuteMarker(2)
+	repeated.AddEndOfMinuteMarker(2)
+	if repeated.MinutesInOrder() {
+		t.Error("Expected a repeated minute to be out of order")
+	}
+}
+
+// Entry referencing an identity chain, standing in for the server entries
+type testIdentityEntry struct {
+	*UnknownABEntry
+	identity *Hash
+}
+
+func (e *testIdentityEntry) IdentityChainID() *Hash {
+	return e.identity
+}
+
+func TestAdminBlockValidateIdentity(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockValidateIdentity\n---\n")
+
+	newEntry := func(identity *Hash) *testIdentityEntry {
+		return &testIdentityEntry{&UnknownABEntry{Data: []byte{0x42}}, identity}
+	}
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(newEntry(Sha([]byte("one"))))
+	if err := block.Validate(); err != nil {
+		t.Errorf("%v", err)
+	}
+
+	zero := newTestAdminBlock(t)
+	zero.AddABEntry(newEntry(NewHash()))
+	if err := zero.Validate(); err != ErrInvalidIdentity {
+		t.Errorf("Expected ErrInvalidIdentity for a zero identity, got %v", err)
+	}
+
+	self := newTestAdminBlock(t)
+	adminChainID := NewHash()
+	adminChainID.SetBytes(ADMIN_CHAINID)
+	self.AddABEntry(newEntry(adminChainID))
+	if err := self.Validate(); err != ErrInvalidIdentity {
+		t.Errorf("Expected ErrInvalidIdentity for the admin chain as identity, got %v", err)
+	}
+
+	// DB signatures are not identity entries and may carry a zero identity
+	signed := newTestAdminBlock(t)
+	signed.AddABEntry(NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(make([]byte, 96))))
+	if err := signed.Validate(); err != nil {
+		t.Errorf("%v", err)
+	}
+}
+
+func TestAdminBlockChecksum(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockChecksum\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	if _, err := block.CRC32Checksum(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if ok, err := block.VerifyChecksum(); !ok || err != nil {
+		t.Errorf("Checksum does not verify: %v", err)
+	}
+
+	block.ABEntries[0].(*EndOfMinuteEntry).EOM_Type = 2
+	if ok, _ := block.VerifyChecksum(); ok {
+		t.Error("Expected a modified block to fail the checksum")
+	}
+}
+
+func TestAdminBlockSetEntryAt(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSetEntryAt\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+	hash, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	sigEntry := NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(make([]byte, 96)))
+	if err := block.SetEntryAt(1, sigEntry); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block.ABEntries[1] != sigEntry {
+		t.Error("Entry was not replaced")
+	}
+	expected := uint32(block.ABEntries[0].MarshalledSize() + sigEntry.MarshalledSize())
+	if block.Header.BodySize != expected {
+		t.Errorf("Expected BodySize %v, got %v", expected, block.Header.BodySize)
+	}
+	hash2, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if hash.IsSameAs(hash2) {
+		t.Error("Expected the hash to change")
+	}
+
+	if err := block.SetEntryAt(2, sigEntry); err != ErrIndexOutOfRange {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	block.Seal()
+	if err := block.SetEntryAt(0, sigEntry); err != ErrBlockSealed {
+		t.Errorf("Expected ErrBlockSealed, got %v", err)
+	}
+}
+
+func TestMarshalABEntries(t *testing.T) {
+	fmt.Printf("\n---\nTestMarshalABEntries\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+
+	data, err := MarshalABEntries(block.ABEntries)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	entries, err := UnmarshalABEntries(data)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", len(entries))
+	}
+	if _, ok := entries[0].(*DBSignatureEntry); !ok {
+		t.Errorf("Expected a DBSignatureEntry, got %T", entries[0])
+	}
+	if eom, ok := entries[1].(*EndOfMinuteEntry); !ok || eom.EOM_Type != 1 {
+		t.Errorf("Unexpected entry %v", entries[1])
+	}
+
+	if _, err := UnmarshalABEntries(data[:len(data)-1]); err == nil {
+		t.Error("Expected an error for truncated data")
+	}
+	if _, err := UnmarshalABEntries(append(data, 0)); err == nil {
+		t.Error("Expected an error for trailing data")
+	}
+}
+
+func TestAdminBlockSplitAt(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSplitAt\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+
+	if _, _, err := block.SplitAt(1); err != ErrNotSplittableAtEOM {
+		t.Errorf("Expected ErrNotSplittableAtEOM, got %v", err)
+	}
+	if _, _, err := block.SplitAt(4); err != ErrIndexOutOfRange {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	first, second, err := block.SplitAt(2)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if first.Header.MessageCount != 2 || second.Header.MessageCount != 1 {
+		t.Errorf("Unexpected MessageCounts %v and %v", first.Header.MessageCount, second.Header.MessageCount)
+	}
+	if first.Header.BodySize+second.Header.BodySize != uint32(block.MarshalledSize()-block.Header.MarshalledSize()) {
+		t.Error("BodySizes do not add up to the original body")
+	}
+	if second.Header.DBHeight != first.Header.DBHeight+1 {
+		t.Errorf("Unexpected DBHeight %v", second.Header.DBHeight)
+	}
+	if ok, err := second.IsChildOf(first); !ok || err != nil {
+		t.Errorf("Second block does not follow the first: %v", err)
+	}
+}
+
+func TestAdminBlockIsPrunable(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockIsPrunable\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	if block.IsPrunable(0) {
+		t.Error("Expected a block at the checkpoint height not to be prunable")
+	}
+	if !block.IsPrunable(1) {
+		t.Error("Expected a block of minute markers below the checkpoint to be prunable")
+	}
+
+	block.AddABEntry(NewDBSignatureEntry(NewHash(), UnmarshalBinarySignature(make([]byte, 96))))
+	if block.IsPrunable(1) {
+		t.Error("Expected a block with a signature not to be prunable")
+	}
+}
+
+func TestABlockHeaderIsEqual(t *testing.T) {
+	fmt.Printf("\n---\nTestABlockHeaderIsEqual\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	block.MarshalBinary()
+
+	header := *block.Header
+	if !block.Header.IsEqual(&header) {
+		t.Error("Expected a copy of the header to be equal")
+	}
+
+	header.BodySize++
+	if block.Header.IsEqual(&header) {
+		t.Error("Expected headers differing in BodySize to differ")
+	}
+
+	header = *block.Header
+	header.MessageCount++
+	if block.Header.IsEqual(&header) {
+		t.Error("Expected headers differing in MessageCount to differ")
+	}
+}
+
+func TestAdminBlockVerifySignatureCount(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockVerifySignatureCount\n---\n")
+
+	block := newTestAdminBlock(t)
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("two")), sig))
+	block.AddEndOfMinuteMarker(1)
+
+	if err := block.VerifySignatureCount(2); err != nil {
+		t.Errorf("%v", err)
+	}
+	err := block.VerifySignatureCount(3)
+	if mismatch, ok := err.(ErrSignatureCountMismatch); !ok || mismatch.Expected != 3 || mismatch.Got != 2 {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestAdminBlockMissingMinutes(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMissingMinutes\n---\n")
+
+	block := newTestAdminBlock(t)
+	for _, minute := range []byte{1, 2, 4, 5, 6, 7, 9} {
+		block.AddEndOfMinuteMarker(minute)
+	}
+
+	if !block.HasEOMForMinute(4) {
+		t.Error("Expected minute 4 to have a marker")
+	}
+	if block.HasEOMForMinute(3) {
+		t.Error("Expected minute 3 to have no marker")
+	}
+	if missing := block.MissingMinutes(); !bytes.Equal(missing, []byte{3, 8, 10}) {
+		t.Errorf("Expected missing minutes [3 8 10], got %v", missing)
+	}
+}
+
+func TestAdminBlockToGraphQLMap(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockToGraphQLMap\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+
+	m := block.ToGraphQLMap()
+	if m["dbHeight"] != uint32(0) {
+		t.Errorf("Unexpected dbHeight %v", m["dbHeight"])
+	}
+	// One 129 byte DB signature and one 2 byte marker
+	if m["messageCount"] != uint32(2) || m["bodySize"] != uint32(131) {
+		t.Errorf("Expected messageCount 2 and bodySize 131, got %v and %v", m["messageCount"], m["bodySize"])
+	}
+	entries := m["entries"].([]interface{})
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", len(entries))
+	}
+	if entries[0].(map[string]interface{})["__typename"] != "DBSignatureEntry" {
+		t.Errorf("Unexpected entry %v", entries[0])
+	}
+	if eom := entries[1].(map[string]interface{}); eom["__typename"] != "EndOfMinuteEntry" || eom["minute"] != byte(1) {
+		t.Errorf("Unexpected entry %v", eom)
+	}
+
+	empty := new(AdminBlock).ToGraphQLMap()
+	if empty["adminChainId"] != nil {
+		t.Errorf("Expected a nil adminChainId, got %v", empty["adminChainId"])
+	}
+}
+
+func TestAdminBlockComputeSignerWeight(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockComputeSignerWeight\n---\n")
+
+	block := newTestAdminBlock(t)
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("two")), sig))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("three")), sig))
+	block.AddEndOfMinuteMarker(1)
+
+	weights := map[string]int{
+		Sha([]byte("one")).String(): 3,
+		Sha([]byte("two")).String(): 2,
+	}
+	if weight, err := block.ComputeSignerWeight(weights, false); weight != 5 || err != nil {
+		t.Errorf("Expected weight 5, got %v %v", weight, err)
+	}
+	if _, err := block.ComputeSignerWeight(weights, true); err == nil {
+		t.Error("Expected an error for an identity without a weight")
+	}
+
+	anonymous := newTestAdminBlock(t)
+	anonymous.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	anonymous.AddABEntry(NewDBSignatureEntry(nil, sig))
+	if weight, err := anonymous.ComputeSignerWeight(weights, false); weight != 3 || err != nil {
+		t.Errorf("Expected weight 3 skipping the signature without identity, got %v %v", weight, err)
+	}
+	if _, err := anonymous.ComputeSignerWeight(weights, true); err != ErrNilIdentity {
+		t.Errorf("Expected ErrNilIdentity, got %v", err)
+	}
+}
+
+func TestAdminBlockMinuteBoundaries(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMinuteBoundaries\n---\n")
+
+	block := newTestAdminBlock(t)
+	if boundaries := block.MinuteBoundaries(); boundaries == nil || len(boundaries) != 0 {
+		t.Errorf("Expected an empty slice for an empty block, got %v", boundaries)
+	}
+
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("two")), sig))
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("three")), sig))
+	block.AddEndOfMinuteMarker(3)
+	// Entries after the last marker belong to no boundary
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("four")), sig))
+
+	want := []MinuteBoundary{
+		{Minute: 1, EntriesBefore: 2},
+		{Minute: 2, EntriesBefore: 0},
+		{Minute: 3, EntriesBefore: 1},
+	}
+	boundaries := block.MinuteBoundaries()
+	if len(boundaries) != len(want) {
+		t.Fatalf("Expected %v boundaries, got %v", want, boundaries)
+	}
+	for i := range want {
+		if boundaries[i] != want[i] {
+			t.Errorf("Boundary %v is %v, want %v", i, boundaries[i], want[i])
+		}
+	}
+}
+
+func TestAdminBlockGroupByMinute(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockGroupByMinute\n---\n")
+
+	block := newTestAdminBlock(t)
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	if _, err := block.GroupByMinute(); err != ErrNoEOMFound {
+		t.Errorf("Expected ErrNoEOMFound, got %v", err)
+	}
+
+	block.AddEndOfMinuteMarker(1)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("two")), sig))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("three")), sig))
+	block.AddEndOfMinuteMarker(2)
+
+	groups, err := block.GroupByMinute()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(groups[0]) != 1 || len(groups[1]) != 2 || len(groups[2]) != 0 {
+		t.Errorf("Unexpected groups %v", groups)
+	}
+}
+
+func TestEntryDedupTracker(t *testing.T) {
+	fmt.Printf("\n---\nTestEntryDedupTracker\n---\n")
+
+	tracker := NewEntryDedupTracker()
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	entry := NewDBSignatureEntry(Sha([]byte("one")), sig)
+
+	if dup, _ := tracker.Seen(entry, 5); dup {
+		t.Error("Expected the first sighting not to be a duplicate")
+	}
+	if dup, _ := tracker.Seen(NewDBSignatureEntry(Sha([]byte("two")), sig), 6); dup {
+		t.Error("Expected a different entry not to be a duplicate")
+	}
+	if dup, height := tracker.Seen(NewDBSignatureEntry(Sha([]byte("one")), sig), 7); !dup || height != 5 {
+		t.Errorf("Expected a duplicate first seen at 5, got %v %v", dup, height)
+	}
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	tracker.Seen(block.ABEntries[0], 5)
+	if dup, _ := tracker.Seen(block.ABEntries[0], 6); dup {
+		t.Error("Expected end-of-minute markers to be ignored")
+	}
+}
+
+func TestAdminBlockCompact(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockCompact\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+	block.AddEndOfMinuteMarker(3)
+	block.ABEntries[1] = nil
+	header := *block.Header
+
+	compact, err := block.Compact()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(compact.ABEntries) != 2 || compact.Header.MessageCount != 2 || compact.Header.BodySize != 4 {
+		t.Errorf("Unexpected compacted block %v entries, header %v", len(compact.ABEntries), compact.Header)
+	}
+	if len(block.ABEntries) != 3 || block.ABEntries[1] != nil || !block.Header.IsEqual(&header) {
+		t.Error("Original block was modified")
+	}
+	if _, err := compact.MarshalBinary(); err != nil {
+		t.Errorf("%v", err)
+	}
+}
+
+func TestAdminBlockIsEqualIgnoresCachedHash(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockIsEqualIgnoresCachedHash\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+
+	clone, err := block.Clone()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, err := block.LedgerKeyMR(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if !block.IsEqual(clone) || !clone.IsEqual(block) {
+		t.Error("Expected a hashed block to equal its unhashed clone")
+	}
+
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	data2, err := clone.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(data, data2) {
+		t.Error("Clone does not marshal to the same bytes")
+	}
+
+	clone.ABEntries[1].(*EndOfMinuteEntry).EOM_Type = 2
+	if block.ABEntries[1].(*EndOfMinuteEntry).EOM_Type != 1 {
+		t.Error("Clone shares entries with the original")
+	}
+}
+
+func TestAdminBlockJSONLines(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockJSONLines\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+
+	lines, err := block.ToJSONLines()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if n := strings.Count(lines, "\n"); n != 2 {
+		t.Errorf("Expected 2 lines, got %v", n)
+	}
+
+	block2, err := AdminBlockFromJSONLines(lines)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	data2, err := block2.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(data, data2) {
+		t.Error("Expected the rebuilt block to marshal like the original")
+	}
+
+	if _, err := AdminBlockFromJSONLines(strings.SplitN(lines, "\n", 2)[1]); err == nil {
+		t.Error("Expected an error for a missing line")
+	}
+}
+
+func TestAdminBlockMarshalAndHash(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMarshalAndHash\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	data, hash, err := block.MarshalAndHash()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Error("MarshalAndHash data differs from MarshalBinary")
+	}
+	if !hash.IsSameAs(Sha(expected)) {
+		t.Error("MarshalAndHash hash differs from the SHA256 of the data")
+	}
+	if partial, _ := block.PartialHash(); !partial.IsSameAs(hash) {
+		t.Error("Expected the hash to be cached as the partial hash")
+	}
+}
+
+func TestAdminBlockRehash(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockRehash\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	if err := block.Rehash(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	oldFull, _ := block.LedgerKeyMR()
+	oldPartial, _ := block.PartialHash()
+
+	// Change the entries behind the cached hashes
+	block.ABEntries = append(block.ABEntries, &EndOfMinuteEntry{EOM_Type: 2})
+	if err := block.Rehash(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block.Header.MessageCount != 2 || block.Header.BodySize != 4 {
+		t.Errorf("Expected MessageCount 2 and BodySize 4, got %v and %v", block.Header.MessageCount, block.Header.BodySize)
+	}
+
+	// The hashes match those of a fresh block with the same entries
+	fresh := newTestAdminBlock(t)
+	fresh.AddEndOfMinuteMarker(1)
+	fresh.AddEndOfMinuteMarker(2)
+	full, _ := block.LedgerKeyMR()
+	partial, _ := block.PartialHash()
+	wantFull, _ := fresh.LedgerKeyMR()
+	wantPartial, _ := fresh.PartialHash()
+	if full.IsSameAs(oldFull) || !full.IsSameAs(wantFull) {
+		t.Errorf("LedgerKeyMR is %v, want %v", full, wantFull)
+	}
+	if partial.IsSameAs(oldPartial) || !partial.IsSameAs(wantPartial) {
+		t.Errorf("Partial hash is %v, want %v", partial, wantPartial)
+	}
+}
+
+func TestAdminBlockFlatten(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockFlatten\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	var want []byte
+	for _, entry := range block.ABEntries {
+		data, err := entry.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		want = append(want, data...)
+	}
+	flat, err := block.Flatten()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(flat, want) {
+		t.Errorf("Flatten returned %x, want %x", flat, want)
+	}
+
+	wiped := NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96)))
+	wiped.Wipe()
+	block.AddABEntry(wiped)
+	if _, err := block.Flatten(); err == nil {
+		t.Error("Expected the error of an entry that fails to marshal")
+	}
+}
+
+func TestAdminBlockSizeWith(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSizeWith\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	before, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	entry := NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96)))
+
+	size := block.SizeWith(entry)
+	after, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("Expected SizeWith to leave the block unchanged")
+	}
+
+	block.AddABEntry(entry)
+	if size != block.MarshalledSize() {
+		t.Errorf("SizeWith returned %v, the block takes %v bytes with the entry", size, block.MarshalledSize())
+	}
+}
+
+func TestAdminBlockValidateSize(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockValidateSize\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	size := block.MarshalledSize()
+	if err := block.ValidateSize(size); err != nil {
+		t.Errorf("%v", err)
+	}
+	if err := block.ValidateSize(size - 1); err == nil {
+		t.Error("Expected an error for an oversized block")
+	}
+
+	saved := ABlockSizeLimits
+	defer func() { ABlockSizeLimits = saved }()
+	ABlockSizeLimits = []ABlockSizeLimit{
+		{ActivationHeight: 0, MaxBytes: size},
+		{ActivationHeight: 100, MaxBytes: size - 1},
+	}
+	if err := block.ValidateSizeAtHeight(); err != nil {
+		t.Errorf("%v", err)
+	}
+	block.Header.DBHeight = 100
+	if err := block.ValidateSizeAtHeight(); err == nil {
+		t.Error("Expected the lower limit to apply from its activation height")
+	}
+}
+
+// Create an admin chain holding n empty blocks
+func newTestAdminChain(t *testing.T, n int) *AdminChain {
+	chain := new(AdminChain)
+	chain.ChainID = NewHash()
+	chain.ChainID.SetBytes(ADMIN_CHAINID)
+
+	var prev *AdminBlock
+	for i := 0; i < n; i++ {
+		block, err := CreateAdminBlock(chain, prev, 5)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		block.AddEndOfMinuteMarker(byte(i%10 + 1))
+		chain.AddABlockToAChain(block)
+		chain.NextBlockHeight++
+		prev = block
+	}
+	return chain
+}
+
+func TestAdminChainNextBlockKey(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminChainNextBlockKey\n---\n")
+
+	chain := newTestAdminChain(t, 2)
+	key := chain.NextBlockKey()
+	want := append(append([]byte(nil), ADMIN_CHAINID...), 0, 0, 0, 2)
+	if !bytes.Equal(key, want) {
+		t.Errorf("Next block key is %x, want %x", key, want)
+	}
+
+	// Keys sort by height, including across a byte boundary
+	heights := []uint32{0, 1, 255, 256, 65536}
+	for i := 1; i < len(heights); i++ {
+		lower := AdminBlockHeightKey(ADMIN_CHAINID, heights[i-1])
+		higher := AdminBlockHeightKey(ADMIN_CHAINID, heights[i])
+		if bytes.Compare(lower, higher) >= 0 {
+			t.Errorf("Expected the key of height %v to sort before that of %v", heights[i-1], heights[i])
+		}
+	}
+}
+
+func TestAdminChainGenesis(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminChainGenesis\n---\n")
+
+	empty := new(AdminChain)
+	if _, err := empty.Genesis(); err != ErrChainEmpty {
+		t.Errorf("Expected ErrChainEmpty, got %v", err)
+	}
+	if _, err := empty.Tip(); err != ErrChainEmpty {
+		t.Errorf("Expected ErrChainEmpty, got %v", err)
+	}
+
+	chain := newTestAdminChain(t, 3)
+	genesis, err := chain.Genesis()
+	if err != nil || genesis != chain.Blocks[0] {
+		t.Errorf("Expected the block at height 0: %v", err)
+	}
+	tip, err := chain.Tip()
+	if err != nil || tip != chain.Blocks[2] {
+		t.Errorf("Expected the block at height 2: %v", err)
+	}
+}
+
+func TestAdminChainTruncate(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminChainTruncate\n---\n")
+
+	chain := newTestAdminChain(t, 5)
+	if err := chain.Truncate(5); err != ErrBlockNotFound {
+		t.Errorf("Expected ErrBlockNotFound, got %v", err)
+	}
+
+	if err := chain.Truncate(2); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(chain.Blocks) != 3 || chain.NextBlockHeight != 3 {
+		t.Errorf("Unexpected chain of %v blocks, next height %v", len(chain.Blocks), chain.NextBlockHeight)
+	}
+	tip, err := chain.Tip()
+	if err != nil || tip.Header.DBHeight != 2 {
+		t.Fatalf("Unexpected tip %v", err)
+	}
+	if chain.NextBlock.Header.DBHeight != 3 {
+		t.Errorf("Unexpected next block height %v", chain.NextBlock.Header.DBHeight)
+	}
+	if ok, err := chain.NextBlock.IsChildOf(tip); !ok || err != nil {
+		t.Errorf("Next block does not follow the tip: %v", err)
+	}
+}
+
+func TestAdminBlockHasFederatedSignatures(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockHasFederatedSignatures\n---\n")
+
+	federated := []*Hash{Sha([]byte("one")), Sha([]byte("two")), Sha([]byte("three"))}
+	block := newTestAdminBlock(t)
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	block.AddABEntry(NewDBSignatureEntry(federated[0], sig))
+
+	quorum, missing := block.HasFederatedSignatures(federated, 2)
+	if quorum || len(missing) != 2 {
+		t.Errorf("Expected no quorum and 2 missing, got %v %v", quorum, missing)
+	}
+	if quorum, _ := block.HasFederatedSignatures(federated, 1); !quorum {
+		t.Error("Expected a quorum for a threshold of 1")
+	}
+
+	block.AddABEntry(NewDBSignatureEntry(federated[2], sig))
+	quorum, missing = block.HasFederatedSignatures(federated, 2)
+	if !quorum || len(missing) != 1 || !missing[0].IsSameAs(federated[1]) {
+		t.Errorf("Expected a quorum with %v missing, got %v %v", federated[1], quorum, missing)
+	}
+	if quorum, _ := block.HasFederatedSignatures(federated, 3); quorum {
+		t.Error("Expected no quorum for a threshold of 3")
+	}
+
+	// Nil identities neither panic nor count
+	block.AddABEntry(NewDBSignatureEntry(nil, sig))
+	quorum, missing = block.HasFederatedSignatures(append(federated, nil), 3)
+	if quorum || len(missing) != 2 || missing[1] != nil {
+		t.Errorf("Expected no quorum with %v and nil missing, got %v %v", federated[1], quorum, missing)
+	}
+}
+
+func TestAdminBlockEntriesSinceLastMinute(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockEntriesSinceLastMinute\n---\n")
+
+	block := newTestAdminBlock(t)
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	if entries := block.EntriesSinceLastMinute(); len(entries) != 1 {
+		t.Errorf("Expected 1 entry, got %v", len(entries))
+	}
+
+	block.AddEndOfMinuteMarker(1)
+	if entries := block.EntriesSinceLastMinute(); len(entries) != 0 {
+		t.Errorf("Expected no entries, got %v", len(entries))
+	}
+
+	two := NewDBSignatureEntry(Sha([]byte("two")), sig)
+	block.AddABEntry(two)
+	if entries := block.EntriesSinceLastMinute(); len(entries) != 1 || entries[0] != two {
+		t.Errorf("Unexpected entries %v", entries)
+	}
+}
+
+func TestAdminBlockValidateSignaturePosition(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockValidateSignaturePosition\n---\n")
+
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), sig))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("two")), sig))
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+	if err := block.Validate(); err != nil {
+		t.Errorf("%v", err)
+	}
+
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("three")), sig))
+	if err := block.Validate(); err != ErrSignatureOutOfPosition {
+		t.Errorf("Expected ErrSignatureOutOfPosition, got %v", err)
+	}
+}
+
+func TestAdminChainEvents(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminChainEvents\n---\n")
+
+	chain := new(AdminChain)
+	chain.ChainID = NewHash()
+	chain.ChainID.SetBytes(ADMIN_CHAINID)
+	events := chain.Events()
+
+	block, err := CreateAdminBlock(chain, nil, 5)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	chain.AddABlockToAChain(block)
+	chain.NextBlockHeight++
+	next, err := CreateAdminBlock(chain, block, 5)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	chain.AddABlockToAChain(next)
+	chain.Truncate(0)
+
+	expected := []AdminBlockEventType{BlockCreated, BlockFinalized, BlockCreated, BlockFinalized, BlockOrphaned, BlockCreated}
+	for i, eventType := range expected {
+		select {
+		case event := <-events:
+			if event.Type != eventType || event.Chain != chain {
+				t.Errorf("Event %v: expected type %v, got %v", i, eventType, event.Type)
+			}
+		default:
+			t.Fatalf("Missing event %v", i)
+		}
+	}
+	select {
+	case event := <-events:
+		t.Errorf("Unexpected event %v", event.Type)
+	default:
+	}
+}
+
+func TestAdminBlockMarshalDBReference(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMarshalDBReference\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+
+	data, err := block.MarshalDBReference()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	hash, err := block.PartialHash()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	expected := append(block.Header.AdminChainID.Bytes(), hash.Bytes()...)
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Expected %x, got %x", expected, data)
+	}
+}
+
+func TestAdminBlockMinuteMarkers(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMinuteMarkers\n---\n")
+
+	block := newTestAdminBlock(t)
+	if err := block.AddEndOfMinuteMarker(0); err != ErrInvalidMinute {
+		t.Errorf("Expected ErrInvalidMinute, got %v", err)
+	}
+	if len(block.ABEntries) != 0 {
+		t.Error("Minute 0 was added to the block")
+	}
+
+	minutes := []byte{MinuteMarker1, MinuteMarker2, MinuteMarker3, MinuteMarker4, MinuteMarker5,
+		MinuteMarker6, MinuteMarker7, MinuteMarker8, MinuteMarker9, MinuteMarker10}
+	for i, minute := range minutes {
+		if err := block.AddEndOfMinuteMarker(minute); err != nil {
+			t.Fatalf("%v", err)
+		}
+		data, err := block.ABEntries[i].MarshalBinary()
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if !bytes.Equal(data, []byte{TYPE_MINUTE_NUM, byte(i + 1)}) {
+			t.Errorf("Minute %v marshalled to %x", i+1, data)
+		}
+	}
+
+	if err := new(EndOfMinuteEntry).UnmarshalBinary([]byte{TYPE_MINUTE_NUM, 0}); err != ErrInvalidMinute {
+		t.Errorf("Expected ErrInvalidMinute, got %v", err)
+	}
+}
+
+func TestAdminBlockPatch(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockPatch\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+	original, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	sigEntry := NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96)))
+	other := newTestAdminBlock(t)
+	other.AddEndOfMinuteMarker(3)
+
+	patched, err := block.Patch([]PatchOp{
+		{Op: "add", Index: 0, Entry: sigEntry},
+		{Op: "remove", Index: 1},
+		{Op: "replace", Index: 1, Entry: other.ABEntries[0]},
+	})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(patched.ABEntries) != 2 || patched.ABEntries[0] != sigEntry || patched.ABEntries[1] != other.ABEntries[0] {
+		t.Errorf("Unexpected entries %v", patched.ABEntries)
+	}
+	if patched.Header.MessageCount != 2 {
+		t.Errorf("Expected MessageCount 2, got %v", patched.Header.MessageCount)
+	}
+
+	if _, err := block.Patch([]PatchOp{{Op: "remove", Index: 0}, {Op: "remove", Index: 5}}); err == nil {
+		t.Error("Expected an error for an out of range index")
+	}
+	if _, err := block.Patch([]PatchOp{{Op: "move", Index: 0}}); err == nil {
+		t.Error("Expected an error for an unknown operation")
+	}
+
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(original, data) {
+		t.Error("Patch modified the original block")
+	}
+}
+
+func TestAdminBlockLinksTo(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockLinksTo\n---\n")
+
+	chain := newTestAdminChain(t, 2)
+	competing := newTestAdminBlock(t)
+	competing.AddEndOfMinuteMarker(5)
+
+	next, err := CreateAdminBlock(chain, chain.Blocks[1], 5)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if ok, err := next.LinksTo(chain.Blocks[1]); !ok || err != nil {
+		t.Errorf("Expected the block to link to its parent: %v", err)
+	}
+	if ok, _ := next.LinksTo(competing); ok {
+		t.Error("Expected the block not to link to a competing block")
+	}
+}
+
+func TestAdminBlockSetPrevHashFrom(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSetPrevHashFrom\n---\n")
+
+	chain := newTestAdminChain(t, 2)
+	prev := chain.Blocks[1]
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	oldHash, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if err := block.SetPrevHashFrom(prev); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if ok, err := block.PrevHashMatches(prev); !ok || err != nil {
+		t.Errorf("Expected the block to link to its predecessor: %v", err)
+	}
+	if block.Header.DBHeight != prev.Header.DBHeight+1 {
+		t.Errorf("Expected DBHeight %v, got %v", prev.Header.DBHeight+1, block.Header.DBHeight)
+	}
+
+	// The hash covers the new header
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	hash, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if hash.IsSameAs(oldHash) || !hash.IsSameAs(Sha512Half(data)) {
+		t.Errorf("Expected the hash to be recomputed, got %v", hash)
+	}
+
+	if err := block.SetPrevHashFrom(nil); err == nil {
+		t.Error("Expected an error for a nil predecessor")
+	}
+}
+
+func TestAdminBlockAddABEntryClearsHash(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockAddABEntryClearsHash\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	oldHash, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	priv := new(PrivateKey)
+	if err := priv.GenerateKey(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := block.AddDBSignature(NewHash(), priv.Key[:], newTestDBlockHeader()); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	hash, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if hash.IsSameAs(oldHash) || !hash.IsSameAs(Sha512Half(data)) {
+		t.Errorf("Expected the hash to cover the new entry, got %v", hash)
+	}
+	if block.Header.MessageCount != 2 || block.Header.BodySize != 131 {
+		t.Errorf("Expected MessageCount 2 and BodySize 131, got %v and %v", block.Header.MessageCount, block.Header.BodySize)
+	}
+}
+
+func TestAdminBlockGetDBSignature(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockGetDBSignature\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	if block.GetDBSignature() != nil {
+		t.Error("Expected no DB signature")
+	}
+
+	entry := NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96)))
+	block.AddABEntry(entry)
+	// The header count does not bound the search
+	block.Header.MessageCount = 0
+	if block.GetDBSignature() != entry {
+		t.Error("Expected the DB signature of a block with a stale header")
+	}
+	block.Header.MessageCount = 10
+	if block.GetDBSignature() != entry {
+		t.Error("Expected the DB signature of a block with an inflated count")
+	}
+}
+
+func TestAdminBlockSwapEntries(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSwapEntries\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+	hash, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	header := *block.Header
+
+	if err := block.SwapEntries(0, 1); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block.ABEntries[0].Type() != TYPE_MINUTE_NUM {
+		t.Error("Entries were not swapped")
+	}
+	if !block.Header.IsEqual(&header) {
+		t.Error("Expected the header to be unchanged")
+	}
+	if hash2, _ := block.LedgerKeyMR(); hash.IsSameAs(hash2) {
+		t.Error("Expected the hash to change")
+	}
+
+	if err := block.SwapEntries(0, 2); err != ErrIndexOutOfRange {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	block.Seal()
+	if err := block.SwapEntries(0, 1); err != ErrBlockSealed {
+		t.Errorf("Expected ErrBlockSealed, got %v", err)
+	}
+}
+
+func TestAdminChainMarshal(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminChainMarshal\n---\n")
+
+	chain := new(AdminChain)
+	chain.ChainID = NewHash()
+	chain.ChainID.SetBytes(ADMIN_CHAINID)
+	chain.Name = [][]byte{[]byte("admin"), {}, []byte("chain")}
+
+	data, err := chain.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if uint64(len(data)) != chain.MarshalledSize() {
+		t.Errorf("Expected %v bytes, got %v", chain.MarshalledSize(), len(data))
+	}
+
+	chain2 := new(AdminChain)
+	if err := chain2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !chain2.ChainID.IsSameAs(chain.ChainID) || chain2.NextBlockHeight != 0 || len(chain2.Name) != 3 ||
+		string(chain2.Name[0]) != "admin" || len(chain2.Name[1]) != 0 || string(chain2.Name[2]) != "chain" {
+		t.Errorf("Unexpected chain %v %v %q", chain2.ChainID, chain2.NextBlockHeight, chain2.Name)
+	}
+
+	if err := new(AdminChain).UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("Expected an error for truncated data")
+	}
+}
+
+func TestAdminChainValidate(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminChainValidate\n---\n")
+
+	chain := newTestAdminChain(t, 4)
+	if err := chain.Validate(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// Break the link from block 2 and the cached hash of block 3
+	chain.Blocks[1].ABEntries[0].(*EndOfMinuteEntry).EOM_Type = 9
+	chain.Blocks[1].Rehash()
+	chain.Blocks[3].LedgerKeyMR()
+	chain.Blocks[3].ABEntries[0].(*EndOfMinuteEntry).EOM_Type = 9
+
+	err := chain.Validate()
+	errs, ok := err.(ChainValidationErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("Expected 2 violations, got %v", err)
+	}
+	if errs[0].Height != 2 || errs[1].Height != 3 {
+		t.Errorf("Unexpected violations %v", errs)
+	}
+}
+
+func TestAdminBlockEntryTypes(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockEntryTypes\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	block.AddEndOfMinuteMarker(2)
+	if types := block.EntryTypes(); !bytes.Equal(types, []byte{TYPE_MINUTE_NUM}) {
+		t.Errorf("Unexpected entry types %v", types)
+	}
+
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	if types := block.EntryTypes(); !bytes.Equal(types, []byte{TYPE_MINUTE_NUM, TYPE_DB_SIGNATURE}) {
+		t.Errorf("Unexpected entry types after adding an entry %v", types)
+	}
+}
+
+func TestAdminBlockMaxEntryType(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMaxEntryType\n---\n")
+
+	block := newTestAdminBlock(t)
+	if _, ok := block.MaxEntryType(); ok {
+		t.Error("Expected no entry type for an empty block")
+	}
+
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+	if max, ok := block.MaxEntryType(); !ok || max != TYPE_DB_SIGNATURE {
+		t.Errorf("Expected %v, got %v", TYPE_DB_SIGNATURE, max)
+	}
+}
+
+func TestAdminBlockCanonicalize(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockCanonicalize\n---\n")
+
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	first := NewDBSignatureEntry(Sha([]byte("a")), sig)
+	second := NewDBSignatureEntry(Sha([]byte("b")), sig)
+	if bytes.Compare(first.IdentityAdminChainID.Bytes(), second.IdentityAdminChainID.Bytes()) > 0 {
+		first, second = second, first
+	}
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(second)
+	block.AddEndOfMinuteMarker(1)
+	block.AddABEntry(first)
+	block.AddEndOfMinuteMarker(2)
+
+	received, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	canonical, err := block.Canonicalize()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if bytes.Equal(received, canonical) {
+		t.Error("Expected a non-canonical block to change")
+	}
+	if block.ABEntries[0] != first || block.ABEntries[1] != second {
+		t.Error("DB signatures are not first and ordered by identity")
+	}
+	if err := block.Validate(); err != nil {
+		t.Errorf("%v", err)
+	}
+
+	again, err := block.Canonicalize()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(again, canonical) {
+		t.Error("Canonicalizing a canonical block changed it")
+	}
+}
+
+func TestAdminBlockTrustedDecode(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockTrustedDecode\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// Break BodySize, which is the last field of the header
+	bad := append([]byte(nil), data...)
+	bodySizeOffset := int(block.Header.MarshalledSize()) - 4
+	binary.BigEndian.PutUint32(bad[bodySizeOffset:], block.Header.BodySize+1)
+
+	if _, err := UnmarshalAdminBlockWithOptions(bad, DefaultDecodeOptions); err == nil {
+		t.Error("Expected a BodySize error")
+	}
+	decoded, err := UnmarshalAdminBlockWithOptions(bad, DecodeOptions{Strict: true, TrustedDecode: true})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(decoded.ABEntries) != 1 {
+		t.Errorf("Expected 1 entry, got %v", len(decoded.ABEntries))
+	}
+}
+
+func TestAdminBlockMarshalsTo(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMarshalsTo\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	buf := make([]byte, len(data)+10)
+	n, err := block.MarshalsTo(buf)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if n != len(data) || !bytes.Equal(buf[:n], data) {
+		t.Errorf("MarshalsTo wrote %x, expected %x", buf[:n], data)
+	}
+
+	if _, err := block.MarshalsTo(buf[:len(data)-1]); err != io.ErrShortBuffer {
+		t.Errorf("Expected io.ErrShortBuffer, got %v", err)
+	}
+
+	// An entry that writes more than its MarshalledSize cannot land in buf
+	block.AddABEntry(&testUnderstatedEntry{&testPaddedEntry{&EndOfMinuteEntry{EOM_Type: 11}}})
+	if _, err := block.MarshalsTo(make([]byte, len(data)+10)); err == nil {
+		t.Error("Expected an error for an entry larger than its MarshalledSize")
+	}
+}
+
+// Entry that writes the padding byte of testPaddedEntry without counting it
+type testUnderstatedEntry struct {
+	*testPaddedEntry
+}
+
+func (e *testUnderstatedEntry) MarshalledSize() uint64 {
+	return e.EndOfMinuteEntry.MarshalledSize()
+}
+
+func TestAdminBlockFilterEntries(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockFilterEntries\n---\n")
+
+	isDBSignature := func(e ABEntry) bool {
+		return e.Type() == TYPE_DB_SIGNATURE
+	}
+
+	block := newTestAdminBlock(t)
+	if entries := block.FilterEntries(isDBSignature); entries == nil || len(entries) != 0 {
+		t.Errorf("Expected an empty slice for an empty block, got %v", entries)
+	}
+
+	block.AddEndOfMinuteMarker(1)
+	if entries := block.FilterEntries(isDBSignature); entries == nil || len(entries) != 0 {
+		t.Errorf("Expected an empty slice when nothing matches, got %v", entries)
+	}
+
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	first := NewDBSignatureEntry(Sha([]byte("one")), sig)
+	second := NewDBSignatureEntry(Sha([]byte("two")), sig)
+	block.AddABEntry(first)
+	block.AddEndOfMinuteMarker(2)
+	block.AddABEntry(second)
+
+	entries := block.FilterEntries(isDBSignature)
+	if len(entries) != 2 || entries[0] != first || entries[1] != second {
+		t.Errorf("Expected the two DB signatures in order, got %v", entries)
+	}
+}
+
+type testCoinbaseEntry struct {
+	*EndOfMinuteEntry
+	outputs []CoinbaseOutput
+}
+
+func (e *testCoinbaseEntry) Outputs() []CoinbaseOutput {
+	return e.outputs
+}
+
+func TestAdminBlockCoinbaseOutputs(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockCoinbaseOutputs\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	if outputs := block.CoinbaseOutputs(); outputs == nil || len(outputs) != 0 {
+		t.Errorf("Expected an empty slice, got %v", outputs)
+	}
+
+	first := CoinbaseOutput{Address: Sha([]byte("one")), Amount: 10}
+	second := CoinbaseOutput{Address: Sha([]byte("two")), Amount: 20}
+	block.AddABEntry(&testCoinbaseEntry{&EndOfMinuteEntry{EOM_Type: 2}, []CoinbaseOutput{first}})
+	block.AddABEntry(&testCoinbaseEntry{&EndOfMinuteEntry{EOM_Type: 3}, []CoinbaseOutput{second}})
+
+	outputs := block.CoinbaseOutputs()
+	if len(outputs) != 2 || outputs[0] != first || outputs[1] != second {
+		t.Errorf("Unexpected outputs %v", outputs)
+	}
+}
+
+func TestAdminBlockShallowClone(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockShallowClone\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddEndOfMinuteMarker(1)
+	block.Header.DBHeight = 5
+
+	view := block.ShallowClone()
+	block.Header.DBHeight = 6
+	block.Header.PrevLedgerKeyMR.SetBytes(Sha([]byte("changed")).Bytes())
+	block.AddEndOfMinuteMarker(2)
+
+	header := view.Header()
+	if header.DBHeight != 5 {
+		t.Errorf("Expected the header snapshot at height 5, got %v", header.DBHeight)
+	}
+	if header.PrevLedgerKeyMR.IsSameAs(block.Header.PrevLedgerKeyMR) {
+		t.Error("The header snapshot shares PrevLedgerKeyMR with the block")
+	}
+	header.DBHeight = 7
+	if view.Header().DBHeight != 5 {
+		t.Error("Modifying the returned header changed the snapshot")
+	}
+
+	if view.EntryCount() != 1 {
+		t.Errorf("Expected 1 entry, got %v", view.EntryCount())
+	}
+	if entry, err := view.EntryAt(0); err != nil || entry != block.ABEntries[0] {
+		t.Errorf("Expected the entries to be shared: %v", err)
+	}
+	if _, err := view.EntryAt(1); err != ErrIndexOutOfRange {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+}
+
+func TestAdminBlockConcurrentLedgerKeyMR(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockConcurrentLedgerKeyMR\n---\n")
+
+	block := createBenchmarkAdminBlock()
+
+	hashes := make([]*Hash, 8)
+	var wg sync.WaitGroup
+	for i := range hashes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hash, err := block.LedgerKeyMR()
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			block.PartialHash()
+			hashes[i] = hash
+		}(i)
+	}
+	wg.Wait()
+
+	for _, hash := range hashes[1:] {
+		if hash != hashes[0] {
+			t.Error("LedgerKeyMR was computed more than once")
+		}
+	}
+}
+
+func TestPeekAdminBlockHeader(t *testing.T) {
+	fmt.Printf("\n---\nTestPeekAdminBlockHeader\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	block.Header.DBHeight = 42
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	decoded := new(AdminBlock)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("%v", err)
+	}
+	header, err := PeekAdminBlockHeader(data)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !header.IsEqual(decoded.Header) {
+		t.Errorf("Expected header %v, got %v", decoded.Header, header)
+	}
+
+	if _, err := PeekAdminBlockHeader(data[:HASH_LENGTH]); err == nil {
+		t.Error("Expected an error for a truncated header")
+	}
+}
+
+func BenchmarkPeekAdminBlockHeader(b *testing.B) {
+	data, _ := createBenchmarkAdminBlock().MarshalBinary()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		PeekAdminBlockHeader(data)
+	}
+}
+
+func TestAdminBlockHeaderSummary(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockHeaderSummary\n---\n")
+
+	chain := newTestAdminChain(t, 2)
+	parent, child := chain.Blocks[0], chain.Blocks[1]
+
+	data, err := parent.MarshalHeaderSummary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	header, hash, err := UnmarshalHeaderSummary(data)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	parentData, err := parent.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	decoded, err := PeekAdminBlockHeader(parentData)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !header.IsEqual(decoded) {
+		t.Errorf("Expected header %v, got %v", decoded, header)
+	}
+	if !hash.IsSameAs(child.Header.PrevLedgerKeyMR) {
+		t.Error("The summary hash does not match the PrevLedgerKeyMR of the child")
+	}
+
+	if _, _, err := UnmarshalHeaderSummary(data[:len(data)-1]); err == nil {
+		t.Error("Expected an error for a truncated summary")
+	}
+}
+
+func TestAdminBlockEntries(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockEntries\n---\n")
+
+	block := newTestAdminBlock(t)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+	before, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	entries, err := block.Entries()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", len(entries))
+	}
+	if entries[0] == block.ABEntries[0] {
+		t.Error("Entries returned a reference to an entry of the block")
+	}
+
+	entries[0].(*DBSignatureEntry).IdentityAdminChainID.SetBytes(Sha([]byte("two")).Bytes())
+	entries[1].(*EndOfMinuteEntry).EOM_Type = 2
+	after, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("Modifying the returned entries changed the block")
+	}
+}
+
+func TestValidateAdminBlockChain(t *testing.T) {
+	fmt.Printf("\n---\nTestValidateAdminBlockChain\n---\n")
+
+	chain := newTestAdminChain(t, 4)
+	if err := ValidateAdminBlockChain(chain.Blocks); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := ValidateAdminBlockChain(chain.Blocks[1:]); err != nil {
+		t.Errorf("A range not starting at genesis should validate: %v", err)
+	}
+
+	expectFailure := func(blocks []*AdminBlock, height uint32) {
+		err := ValidateAdminBlockChain(blocks)
+		if e, ok := err.(ChainValidationError); !ok || e.Height != height {
+			t.Errorf("Expected a failure at height %v, got %v", height, err)
+		}
+	}
+
+	expectFailure([]*AdminBlock{chain.Blocks[0], chain.Blocks[2]}, 2)
+
+	// A cached hash must not hide a change of the contents
+	chain.Blocks[1].LedgerKeyMR()
+	chain.Blocks[1].ABEntries[0].(*EndOfMinuteEntry).EOM_Type = 5
+	expectFailure(chain.Blocks, 2)
+}
+
+func TestAdminBlockAppendEntries(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockAppendEntries\n---\n")
+
+	sigs := newTestAdminBlock(t)
+	sigs.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	minutes := newTestAdminBlock(t)
+	minutes.AddEndOfMinuteMarker(1)
+	minutes.AddEndOfMinuteMarker(2)
+
+	before, err := sigs.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := sigs.AppendEntries(minutes); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if sigs.Header.MessageCount != 3 {
+		t.Errorf("Expected MessageCount 3, got %v", sigs.Header.MessageCount)
+	}
+	if sigs.Header.BodySize != uint32(sigs.ABEntries[0].MarshalledSize()+2*minutes.ABEntries[0].MarshalledSize()) {
+		t.Errorf("Unexpected BodySize %v", sigs.Header.BodySize)
+	}
+	if after, err := sigs.LedgerKeyMR(); err != nil || after.IsSameAs(before) {
+		t.Errorf("Expected the cached hash to be cleared: %v", err)
+	}
+
+	sigs.Seal()
+	if err := sigs.AppendEntries(minutes); err != ErrBlockSealed {
+		t.Errorf("Expected ErrBlockSealed, got %v", err)
+	}
+}
+
+func TestAdminBlockObserveOp(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockObserveOp\n---\n")
+
+	ops := make([]string, 0)
+	sizes := make([]int, 0)
+	ObserveOp = func(op string, size int, dur time.Duration) {
+		ops = append(ops, op)
+		sizes = append(sizes, size)
+	}
+	defer func() { ObserveOp = nil }()
+
+	block := createBenchmarkAdminBlock()
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := new(AdminBlock).UnmarshalBinary(data); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if len(ops) != 2 || ops[0] != "MarshalBinary" || ops[1] != "UnmarshalBinary" {
+		t.Fatalf("Unexpected observed operations %v", ops)
+	}
+	if sizes[0] != len(data) || sizes[1] != len(data) {
+		t.Errorf("Expected sizes of %v, got %v", len(data), sizes)
+	}
+}
+
+// Entry whose binary has a trailing byte its decoder does not read
+type testPaddedEntry struct {
+	*EndOfMinuteEntry
+}
+
+func (e *testPaddedEntry) MarshalBinary() ([]byte, error) {
+	data, err := e.EndOfMinuteEntry.MarshalBinary()
+	return append(data, 0), err
+}
+
+func (e *testPaddedEntry) MarshalledSize() uint64 {
+	return e.EndOfMinuteEntry.MarshalledSize() + 1
+}
+
+func TestAdminBlockRoundTripTest(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockRoundTripTest\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	if err := block.RoundTripTest(); err != nil {
+		t.Errorf("%v", err)
+	}
+
+	block.AddABEntry(&testPaddedEntry{&EndOfMinuteEntry{EOM_Type: 11}})
+	if err := block.RoundTripTest(); err == nil {
+		t.Error("Expected a block that does not round trip to fail")
+	}
+}
+
+func TestDBSignatureEntryWipe(t *testing.T) {
+	fmt.Printf("\n---\nTestDBSignatureEntryWipe\n---\n")
+
+	sigBytes := make([]byte, 96)
+	for i := range sigBytes {
+		sigBytes[i] = byte(i + 1)
+	}
+	entry := NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(sigBytes))
+	sig := entry.PrevDBSig
+
+	entry.Wipe()
+	if entry.PrevDBSig != nil {
+		t.Error("Expected PrevDBSig to be nil")
+	}
+	if *sig != (Sig{}) {
+		t.Errorf("Expected the signature bytes to be zeroed, got %x", sig[:])
+	}
+	if _, err := entry.VerifySignature(newTestDBlockHeader(), nil, 0); err == nil {
+		t.Error("Expected a wiped entry to fail verification")
+	}
+	if _, err := entry.MarshalBinary(); err == nil {
+		t.Error("Expected a wiped entry to fail marshalling")
+	}
+	if entry.Hash() != nil {
+		t.Error("Expected a nil hash for a wiped entry")
+	}
+
+	// A block holding the wiped entry reports errors instead of panicking
+	block := newTestAdminBlock(t)
+	block.AddABEntry(entry)
+	if _, err := block.MarshalBinary(); err == nil {
+		t.Error("Expected a block with a wiped entry to fail marshalling")
+	}
+	if _, err := block.LedgerKeyMR(); err == nil {
+		t.Error("Expected a block with a wiped entry to fail hashing")
+	}
+	entry.Wipe()
+}
+
+func TestAdminChainNewBlockForHeight(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminChainNewBlockForHeight\n---\n")
+
+	chain := newTestAdminChain(t, 4)
+	chain.Blocks[2] = nil
+	nextHeight := chain.NextBlockHeight
+
+	if _, err := chain.NewBlockForHeight(1); err != ErrBlockAlreadyExists {
+		t.Errorf("Expected ErrBlockAlreadyExists, got %v", err)
+	}
+	if _, err := chain.NewBlockForHeight(10); err != ErrBlockNotFound {
+		t.Errorf("Expected ErrBlockNotFound, got %v", err)
+	}
+
+	block, err := chain.NewBlockForHeight(2)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block.Header.DBHeight != 2 {
+		t.Errorf("Expected DBHeight 2, got %v", block.Header.DBHeight)
+	}
+	if ok, err := block.IsChildOf(chain.Blocks[1]); !ok || err != nil {
+		t.Errorf("Expected the block to follow block 1: %v", err)
+	}
+	if chain.NextBlockHeight != nextHeight || chain.NextBlock != nil {
+		t.Error("NewBlockForHeight changed the next block of the chain")
+	}
+}
+
+func TestAdminBlockUnmarshalDoesNotAlias(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockUnmarshalDoesNotAlias\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	block.Header.HeaderExpansionArea = []byte{1, 2, 3}
+	block.Header.HeaderExpansionSize = 3
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	buf := append([]byte(nil), data...)
+	decoded := new(AdminBlock)
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatalf("%v", err)
+	}
+	for i := range buf {
+		buf[i] = 0xff
+	}
+
+	after, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(after, data) {
+		t.Error("The decoded block changed with its input buffer")
+	}
+}
+
+func TestAdminBlockMarshalBinaryWithOptions(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockMarshalBinaryWithOptions\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if withDefaults, err := block.MarshalBinaryWithOptions(DefaultMarshalOptions); err != nil || !bytes.Equal(withDefaults, data) {
+		t.Errorf("Expected the default options to match MarshalBinary: %v", err)
+	}
+
+	withChecksum, err := block.MarshalBinaryWithOptions(MarshalOptions{IncludeChecksum: true})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	checksum, err := block.CRC32Checksum()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(withChecksum[:len(data)], data) || binary.BigEndian.Uint32(withChecksum[len(data):]) != checksum {
+		t.Errorf("Expected the binary followed by the checksum %x, got %x", checksum, withChecksum[len(data):])
+	}
+
+	if _, err := block.MarshalBinaryWithOptions(MarshalOptions{Version: 1}); err == nil {
+		t.Error("Expected an error for an unknown version")
+	}
+	if _, err := block.MarshalBinaryWithOptions(MarshalOptions{CompressEntries: true}); err == nil {
+		t.Error("Expected an error for compressed entries")
+	}
+}
+
+func TestDBSignatureEntryOwnsSignature(t *testing.T) {
+	fmt.Printf("\n---\nTestDBSignatureEntryOwnsSignature\n---\n")
+
+	sigBytes := make([]byte, 96)
+	for i := range sigBytes {
+		sigBytes[i] = byte(i + 1)
+	}
+	sig := UnmarshalBinarySignature(sigBytes)
+	entry := NewDBSignatureEntry(Sha([]byte("one")), sig)
+	sig.Sig[0] = 0
+	if entry.PrevDBSig[0] != sigBytes[32] {
+		t.Error("The entry shares its signature with the Signature it was created from")
+	}
+
+	data, err := entry.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	want := *entry.PrevDBSig
+
+	decoded := new(DBSignatureEntry)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("%v", err)
+	}
+	for i := range data {
+		data[i] = 0xff
+	}
+	if *decoded.PrevDBSig != want {
+		t.Error("PrevDBSig changed with the buffer it was decoded from")
+	}
+}
+
+func TestAdminBlockFirstAndLastEntryOfType(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockFirstAndLastEntryOfType\n---\n")
+
+	block := newTestAdminBlock(t)
+	if _, _, found := block.FirstEntryOfType(TYPE_DB_SIGNATURE); found {
+		t.Error("Expected no entry in an empty block")
+	}
+	if _, _, found := block.LastEntryOfType(TYPE_DB_SIGNATURE); found {
+		t.Error("Expected no entry in an empty block")
+	}
+
+	block.AddEndOfMinuteMarker(1)
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("one")), UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddABEntry(NewDBSignatureEntry(Sha([]byte("two")), UnmarshalBinarySignature(make([]byte, 96))))
+
+	entry, i, found := block.FirstEntryOfType(TYPE_DB_SIGNATURE)
+	if !found || i != 1 || entry != block.ABEntries[1] {
+		t.Errorf("Expected the entry at index 1, got index %v", i)
+	}
+	entry, i, found = block.LastEntryOfType(TYPE_DB_SIGNATURE)
+	if !found || i != 2 || entry != block.ABEntries[2] {
+		t.Errorf("Expected the entry at index 2, got index %v", i)
+	}
+	if _, i, _ = block.LastEntryOfType(TYPE_MINUTE_NUM); i != 0 {
+		t.Errorf("Expected the only minute marker at index 0, got index %v", i)
+	}
+}
+
+func TestAdminBlockCacheKey(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockCacheKey\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	clone, err := block.Clone()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block.CacheKey() != clone.CacheKey() {
+		t.Error("Expected equal blocks to share a cache key")
+	}
+
+	clone.AddEndOfMinuteMarker(1)
+	if block.CacheKey() == clone.CacheKey() {
+		t.Error("Expected different blocks to have different cache keys")
+	}
+
+	// Once hashed, the key is taken from the LedgerKeyMR
+	hash, err := block.LedgerKeyMR()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if block.CacheKey() != binary.BigEndian.Uint64(hash.Bytes()[:8]) {
+		t.Errorf("Expected the cache key of a hashed block to be the prefix of %v", hash)
+	}
+}
+
+func TestAdminBlockSerializeTo(t *testing.T) {
+	fmt.Printf("\n---\nTestAdminBlockSerializeTo\n---\n")
+
+	block := createBenchmarkAdminBlock()
+	data, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if serialized, err := block.SerializeTo("binary"); err != nil || !bytes.Equal(serialized, data) {
+		t.Errorf("Expected the binary format to match MarshalBinary: %v", err)
+	}
+
+	jsonData, err := block.JSONByte()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if serialized, err := block.SerializeTo("json"); err != nil || !bytes.Equal(serialized, jsonData) {
+		t.Errorf("Expected the json format to match JSONByte: %v", err)
+	}
+
+	cborData, err := block.SerializeTo("cbor")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	// A CBOR map of the five fields of AdminBlockMsg
+	if cborData[0] != 0xa5 {
+		t.Errorf("Expected a CBOR map of 5 pairs, got %x", cborData[:1])
+	}
+	decoded := new(AdminBlock)
+	if err := decoded.UnmarshalCBOR(cborData); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !decoded.IsEqual(block) {
+		t.Error("Expected the cbor format to round trip")
+	}
+
+	for _, format := range []string{"yaml", ""} {
+		if _, err := block.SerializeTo(format); err == nil {
+			t.Errorf("Expected an error for format %q", format)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Size of an admin chain checkpoint: the ChainID, the NextBlockHeight and
+// the LedgerKeyMR of the tip block
+const adminChainCheckpointSize = HASH_LENGTH + 4 + HASH_LENGTH
+
+// Snapshot the tip state of the admin chain so that a node can resume
+// without replaying the chain: the ChainID, NextBlockHeight and the
+// LedgerKeyMR of the block at NextBlockHeight-1, zero for an empty chain.
+func (c *AdminChain) Checkpoint() ([]byte, error) {
+	c.BlockMutex.RLock()
+	defer c.BlockMutex.RUnlock()
+
+	if c.ChainID == nil {
+		return nil, errors.New("Admin chain has no ChainID")
+	}
+
+	tipHash := NewHash()
+	if c.NextBlockHeight > 0 {
+		height := c.NextBlockHeight - 1
+		if height >= uint32(len(c.Blocks)) || c.Blocks[height] == nil {
+			return nil, fmt.Errorf("No tip block at height %v", height)
+		}
+		var err error
+		tipHash, err = c.Blocks[height].LedgerKeyMR()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data := make([]byte, 0, adminChainCheckpointSize)
+	data = append(data, c.ChainID.Bytes()...)
+	data = appendUint32(data, c.NextBlockHeight)
+	data = append(data, tipHash.Bytes()...)
+	return data, nil
+}
+
+// Restore an admin chain from a checkpoint written by Checkpoint.
+// The chain holds no blocks; its NextBlock is an empty block linked to
+// the checkpointed tip, ready to be filled.
+func LoadAdminChainCheckpoint(data []byte) (*AdminChain, error) {
+	if len(data) != adminChainCheckpointSize {
+		return nil, fmt.Errorf("Invalid admin chain checkpoint length %v", len(data))
+	}
+	if !bytes.Equal(data[:HASH_LENGTH], ADMIN_CHAINID) {
+		return nil, errors.New("Checkpoint is not for the admin chain")
+	}
+
+	c := new(AdminChain)
+	c.ChainID = NewHash()
+	c.ChainID.SetBytes(data[:HASH_LENGTH])
+	c.NextBlockHeight = binary.BigEndian.Uint32(data[HASH_LENGTH:])
+
+	b := new(AdminBlock)
+	b.Header = new(ABlockHeader)
+	b.Header.AdminChainID = c.ChainID
+	b.Header.PrevLedgerKeyMR = NewHash()
+	b.Header.PrevLedgerKeyMR.SetBytes(data[HASH_LENGTH+4:])
+	b.Header.DBHeight = c.NextBlockHeight
+	b.ABEntries = make([]ABEntry, 0)
+	c.NextBlock = b
+
+	return c, nil
+}
@@ -0,0 +1,54 @@
+package common_test
+
+import (
+	"testing"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+func TestAdminChainCheckpoint(t *testing.T) {
+	chain := new(AdminChain)
+	chain.ChainID = NewHash()
+	chain.ChainID.SetBytes(ADMIN_CHAINID)
+
+	var prev *AdminBlock
+	for i := 0; i < 3; i++ {
+		block, err := CreateAdminBlock(chain, prev, 5)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		block.AddEndOfMinuteMarker(byte(i + 1))
+		chain.AddABlockToAChain(block)
+		chain.NextBlockHeight++
+		prev = block
+	}
+
+	data, err := chain.Checkpoint()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	loaded, err := LoadAdminChainCheckpoint(data)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if !loaded.ChainID.IsSameAs(chain.ChainID) || loaded.NextBlockHeight != 3 {
+		t.Errorf("Unexpected chain %v at height %v", loaded.ChainID, loaded.NextBlockHeight)
+	}
+	if loaded.NextBlock == nil || loaded.NextBlock.Header.DBHeight != 3 {
+		t.Fatal("Expected the next block to be ready at height 3")
+	}
+	if ok, err := loaded.NextBlock.PrevHashMatches(prev); !ok || err != nil {
+		t.Errorf("Expected the next block to link to the tip: %v", err)
+	}
+
+	// A checkpoint of another chain is rejected
+	other := append([]byte(nil), data...)
+	other[0] = 1
+	if _, err := LoadAdminChainCheckpoint(other); err == nil {
+		t.Error("Expected an error for a checkpoint of another chain")
+	}
+	if _, err := LoadAdminChainCheckpoint(data[1:]); err == nil {
+		t.Error("Expected an error for a truncated checkpoint")
+	}
+}
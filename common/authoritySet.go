@@ -0,0 +1,108 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Authority state built by applying admin blocks in order.
+// For now the only entries with an effect are the DB signatures, which
+// record the last height each identity signed at.
+type AuthoritySet struct {
+	LastSigned map[[HASH_LENGTH]byte]uint32 // Keyed by identity chain id
+
+	applied map[uint32]*Hash // LedgerKeyMR of the block applied at each height
+	mutex   sync.RWMutex
+}
+
+func NewAuthoritySet() *AuthoritySet {
+	s := new(AuthoritySet)
+	s.LastSigned = make(map[[HASH_LENGTH]byte]uint32)
+	s.applied = make(map[uint32]*Hash)
+	return s
+}
+
+// Apply the effects of the admin block to the authority set.
+// Applying a block that was already applied is a no-op reported by
+// returning false, so that the last block can be reprocessed safely on
+// recovery.  Applying a different block at an applied height fails.
+// End-of-minute markers have no effect.
+func (s *AuthoritySet) ApplyAdminBlock(b *AdminBlock) (bool, error) {
+	hash, err := b.LedgerKeyMR()
+	if err != nil {
+		return false, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	height := b.Header.DBHeight
+	if prev, ok := s.applied[height]; ok {
+		if prev.IsSameAs(hash) {
+			return false, nil
+		}
+		return false, fmt.Errorf("Block %v was already applied at height %v", prev.String(), height)
+	}
+
+	for _, entry := range b.ABEntries {
+		switch e := entry.(type) {
+		case *DBSignatureEntry:
+			s.LastSigned[e.IdentityAdminChainID.bytes] = height
+		}
+	}
+	s.applied[height] = hash
+
+	return true, nil
+}
+
+// Check whether a block was applied at height
+func (s *AuthoritySet) IsApplied(height uint32) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, ok := s.applied[height]
+	return ok
+}
+
+// Rebuild the authority set by applying the admin blocks from height 0 to
+// upto inclusive, as returned by load.  Each block must be stored at its
+// DBHeight and link to the block before it.
+func ReplayAdminChain(load func(height uint32) (*AdminBlock, error), upto uint32) (*AuthoritySet, error) {
+	s := NewAuthoritySet()
+
+	var prev *AdminBlock
+	for height := uint32(0); ; height++ {
+		b, err := load(height)
+		if err != nil {
+			return nil, fmt.Errorf("Replaying admin block %v: %v", height, err)
+		}
+		if b == nil || b.Header == nil {
+			return nil, fmt.Errorf("Replaying admin block %v: no block", height)
+		}
+		if b.Header.DBHeight != height {
+			return nil, fmt.Errorf("Replaying admin block %v: stored with DBHeight %v", height, b.Header.DBHeight)
+		}
+		if prev != nil {
+			ok, err := b.PrevHashMatches(prev)
+			if err != nil {
+				return nil, fmt.Errorf("Replaying admin block %v: %v", height, err)
+			}
+			if !ok {
+				return nil, fmt.Errorf("Replaying admin block %v: does not link to block %v", height, height-1)
+			}
+		}
+
+		if _, err := s.ApplyAdminBlock(b); err != nil {
+			return nil, fmt.Errorf("Replaying admin block %v: %v", height, err)
+		}
+
+		if height == upto {
+			return s, nil
+		}
+		prev = b
+	}
+}
@@ -0,0 +1,102 @@
+package common_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+func TestAuthoritySetApplyAdminBlock(t *testing.T) {
+	chain := new(AdminChain)
+	chain.ChainID = NewHash()
+	chain.ChainID.SetBytes(ADMIN_CHAINID)
+	block, err := CreateAdminBlock(chain, nil, 5)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	identity := Sha([]byte("identity"))
+	block.AddABEntry(NewDBSignatureEntry(identity, UnmarshalBinarySignature(make([]byte, 96))))
+	block.AddEndOfMinuteMarker(1)
+
+	set := NewAuthoritySet()
+	if applied, err := set.ApplyAdminBlock(block); !applied || err != nil {
+		t.Fatalf("Expected the block to be applied: %v", err)
+	}
+	if !set.IsApplied(0) {
+		t.Error("Expected height 0 to be applied")
+	}
+
+	// Reprocessing the same block is a no-op
+	if applied, err := set.ApplyAdminBlock(block); applied || err != nil {
+		t.Errorf("Expected the second apply to be a no-op, got %v %v", applied, err)
+	}
+
+	other, err := CreateAdminBlock(chain, nil, 5)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	other.AddEndOfMinuteMarker(2)
+	if _, err := set.ApplyAdminBlock(other); err == nil {
+		t.Error("Expected an error for a different block at an applied height")
+	}
+}
+
+func TestReplayAdminChain(t *testing.T) {
+	chain := new(AdminChain)
+	chain.ChainID = NewHash()
+	chain.ChainID.SetBytes(ADMIN_CHAINID)
+
+	sig := UnmarshalBinarySignature(make([]byte, 96))
+	identities := []*Hash{Sha([]byte("one")), Sha([]byte("two")), Sha([]byte("one"))}
+	var prev *AdminBlock
+	for _, identity := range identities {
+		block, err := CreateAdminBlock(chain, prev, 5)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		block.AddABEntry(NewDBSignatureEntry(identity, sig))
+		block.AddEndOfMinuteMarker(1)
+		chain.AddABlockToAChain(block)
+		chain.NextBlockHeight++
+		prev = block
+	}
+	load := func(height uint32) (*AdminBlock, error) {
+		if height >= uint32(len(chain.Blocks)) {
+			return nil, ErrBlockNotFound
+		}
+		return chain.Blocks[height], nil
+	}
+
+	key := func(h *Hash) (k [HASH_LENGTH]byte) {
+		copy(k[:], h.Bytes())
+		return
+	}
+
+	set, err := ReplayAdminChain(load, 1)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if set.IsApplied(2) || set.LastSigned[key(identities[0])] != 0 {
+		t.Error("Expected the replay to stop at height 1")
+	}
+
+	set, err = ReplayAdminChain(load, 2)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if set.LastSigned[key(identities[0])] != 2 || set.LastSigned[key(identities[1])] != 1 {
+		t.Errorf("Unexpected last signed heights %v", set.LastSigned)
+	}
+
+	_, err = ReplayAdminChain(load, 5)
+	if err == nil || !strings.Contains(err.Error(), "admin block 3") {
+		t.Errorf("Expected an error at height 3, got %v", err)
+	}
+
+	chain.Blocks[1].SetEntryAt(1, &EndOfMinuteEntry{EOM_Type: 2})
+	_, err = ReplayAdminChain(load, 2)
+	if err == nil || !strings.Contains(err.Error(), "admin block 2") {
+		t.Errorf("Expected a link error at height 2, got %v", err)
+	}
+}
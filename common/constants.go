@@ -28,6 +28,8 @@ const (
 	MAX_ENTRY_CREDITS = uint8(10) //Max number of entry credits per entry
 	MAX_CHAIN_CREDITS = uint8(20) //Max number of entry credits per chain
 
+	MAX_ABLOCK_SIZE = uint64(1 << 20) //Maximum serialized Admin Block size
+
 	COMMIT_TIME_WINDOW = time.Duration(12) //Time windows for commit chain and commit entry +/- 12 hours
 
 	// maxProtocolVersion is the max protocol version the peer supports.
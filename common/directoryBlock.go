@@ -489,9 +489,7 @@ func (c *DChain) AddECBlockToDBEntry(ecb *ECBlock) (err error) {
 // Add DBEntry from an Admin Block
 func (c *DChain) AddABlockToDBEntry(b *AdminBlock) (err error) {
 
-	dbEntry := &DBEntry{}
-	dbEntry.ChainID = b.Header.AdminChainID
-	dbEntry.KeyMR, err = b.PartialHash()
+	dbEntry, err := b.dbReference()
 	if err != nil {
 		return
 	}
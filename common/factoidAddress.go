@@ -0,0 +1,97 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Prefix of human readable Factoid addresses, encoding to "FA"
+var FactoidAddressPrefix = []byte{0x5f, 0xb1}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Format the RCD hash of a Factoid address as a human readable "FA" address:
+// the base58 encoding of the prefix, the hash and the first 4 bytes of the
+// double SHA256 of the two.  A nil hash formats as the empty string.
+func FormatFactoidAddress(h *Hash) string {
+	if h == nil {
+		return ""
+	}
+	data := make([]byte, 0, len(FactoidAddressPrefix)+HASH_LENGTH+4)
+	data = append(data, FactoidAddressPrefix...)
+	data = append(data, h.bytes[:]...)
+	data = append(data, DoubleSha(data)[:4]...)
+	return base58Encode(data)
+}
+
+// Parse a human readable "FA" address into its RCD hash
+func ParseFactoidAddress(addr string) (*Hash, error) {
+	data, err := base58Decode(addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != len(FactoidAddressPrefix)+HASH_LENGTH+4 {
+		return nil, fmt.Errorf("Invalid Factoid address length %v", len(data))
+	}
+	if !bytes.Equal(data[:len(FactoidAddressPrefix)], FactoidAddressPrefix) {
+		return nil, errors.New("Invalid Factoid address prefix")
+	}
+
+	body, checksum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(DoubleSha(body)[:4], checksum) {
+		return nil, errors.New("Invalid Factoid address checksum")
+	}
+
+	h := new(Hash)
+	copy(h.bytes[:], body[len(FactoidAddressPrefix):])
+	return h, nil
+}
+
+func base58Encode(data []byte) string {
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	encoded := make([]byte, 0, len(data)*138/100+1)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+	// Leading zero bytes are encoded as leading '1's
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(s); i++ {
+		digit := bytes.IndexByte([]byte(base58Alphabet), s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("Invalid base58 character %q", s[i])
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,57 @@
+package common_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+func TestFactoidAddress(t *testing.T) {
+	h := Sha([]byte("rcd"))
+
+	addr := FormatFactoidAddress(h)
+	if !strings.HasPrefix(addr, "FA") || len(addr) != 52 {
+		t.Fatalf("Unexpected address %v", addr)
+	}
+
+	h2, err := ParseFactoidAddress(addr)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !h.IsSameAs(h2) {
+		t.Errorf("Expected %v, got %v", h, h2)
+	}
+
+	// Change the last character to break the checksum
+	bad := addr[:len(addr)-1] + "2"
+	if strings.HasSuffix(addr, "2") {
+		bad = addr[:len(addr)-1] + "3"
+	}
+	if _, err := ParseFactoidAddress(bad); err == nil {
+		t.Error("Expected a checksum error")
+	}
+	if _, err := ParseFactoidAddress("FA0"); err == nil {
+		t.Error("Expected an error for an invalid character")
+	}
+}
+
+func TestCoinbaseOutputFactoidAddress(t *testing.T) {
+	if FormatFactoidAddress(nil) != "" {
+		t.Error("Expected an empty address for a nil hash")
+	}
+
+	output := CoinbaseOutput{Address: Sha([]byte("rcd")), Amount: 10}
+	addr := FormatFactoidAddress(output.Address)
+	if output.String() != addr+" 10" {
+		t.Errorf("Unexpected output %v", output.String())
+	}
+
+	data, err := output.MarshalJSON()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(data) != `{"Address":"`+addr+`","Amount":10}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+}
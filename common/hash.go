@@ -6,10 +6,13 @@ package common
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
+
+	"golang.org/x/crypto/scrypt"
 )
 
 type Hash struct {
@@ -71,6 +74,9 @@ func (h *Hash) UnmarshalBinaryData(p []byte) (newData []byte, err error) {
 			err = fmt.Errorf("Error unmarshalling: %v", r)
 		}
 	}()
+	if len(p) < HASH_LENGTH {
+		return nil, fmt.Errorf("Hash needs %v bytes, got %v", HASH_LENGTH, len(p))
+	}
 	copy(h.bytes[:], p)
 	newData = p[HASH_LENGTH:]
 	return
@@ -130,6 +136,41 @@ func Sha512Half(p []byte) (h *Hash) {
 	return h
 }
 
+// Create a HMAC-SHA256 Hash of data with the given key
+func HMACHash(key, data []byte) (h *Hash) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	h = new(Hash)
+	copy(h.bytes[:], mac.Sum(nil))
+	return h
+}
+
+// HMAC-SHA256 of the hash bytes with the given key
+func (h *Hash) HMAC(key []byte) *Hash {
+	return HMACHash(key, h.bytes[:])
+}
+
+// Smallest scrypt cost parameter accepted by HashScrypt
+const MinScryptN = 16384
+
+// Derive a Hash from data and salt with scrypt, for example to generate a
+// reproducible chain ID from a human readable name
+func HashScrypt(data []byte, salt []byte, N, r, p int) (*Hash, error) {
+	if N < MinScryptN {
+		return nil, fmt.Errorf("Insecure scrypt parameter N %v, must be at least %v", N, MinScryptN)
+	}
+
+	key, err := scrypt.Key(data, salt, N, r, p, HASH_LENGTH)
+	if err != nil {
+		return nil, err
+	}
+
+	h := new(Hash)
+	copy(h.bytes[:], key)
+	return h, nil
+}
+
 // Convert a hash into a string with hex encoding
 func (h *Hash) String() string {
 	if h == nil {
@@ -139,6 +180,22 @@ func (h *Hash) String() string {
 	}
 }
 
+// Return a copy of the first n bytes of the hash, for short identifiers.
+// Panics if n is negative or larger than HASH_LENGTH.
+func (h *Hash) Truncate(n int) []byte {
+	if n < 0 || n > HASH_LENGTH {
+		panic(fmt.Sprintf("Cannot truncate a hash to %v bytes, outside 0 to %v", n, HASH_LENGTH))
+	}
+	truncated := make([]byte, n)
+	copy(truncated, h.bytes[:n])
+	return truncated
+}
+
+// Hex encoding of the first n bytes of the hash
+func (h *Hash) TruncatedHex(n int) string {
+	return hex.EncodeToString(h.Truncate(n))
+}
+
 func (h *Hash) ByteString() string {
 	return string(h.bytes[:])
 }
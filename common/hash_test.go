@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	. "github.com/FactomProject/FactomCode/common"
+	"strings"
 	"testing"
 )
 
@@ -119,3 +120,90 @@ func TestIsSameAs(t *testing.T) {
 		t.Error("Identical hashes not recognized as such")
 	}
 }
+
+//Test vectors: https://tools.ietf.org/html/rfc4231
+
+func TestHMACHash(t *testing.T) {
+	type vector struct {
+		key, data, mac string
+	}
+	testVectors := []vector{
+		{"0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b", "4869205468657265",
+			"b0344c61d8db38535ca8afceaf0bf12b881dc200c9833da726e9376c2e32cff7"},
+		{"4a656665", "7768617420646f2079612077616e7420666f72206e6f7468696e673f",
+			"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd",
+			"773ea91e36800e46854db8ebd09181a72959098b3ef8c122d9635514ced565fe"},
+	}
+
+	for _, v := range testVectors {
+		key, err := DecodeBinary(&v.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := DecodeBinary(&v.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mac := HMACHash(key, data).String(); mac != v.mac {
+			t.Errorf("HMAC %v, want %v", mac, v.mac)
+		}
+	}
+
+	h := Sha([]byte("abc"))
+	if !h.HMAC([]byte("key")).IsSameAs(HMACHash([]byte("key"), h.Bytes())) {
+		t.Error("Hash.HMAC does not match HMACHash of the hash bytes")
+	}
+}
+
+func TestHashScrypt(t *testing.T) {
+	// RFC 7914 test vector, truncated to HASH_LENGTH
+	h, err := HashScrypt([]byte("pleaseletmein"), []byte("SodiumChloride"), 16384, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.String() != "7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2" {
+		t.Errorf("Unexpected scrypt hash %v", h)
+	}
+
+	if _, err := HashScrypt([]byte("password"), []byte("NaCl"), 1024, 8, 16); err == nil {
+		t.Error("Expected an error for an insecure N")
+	}
+}
+
+func TestHashUnmarshalShort(t *testing.T) {
+	h := new(Hash)
+	if err := h.UnmarshalBinary(make([]byte, HASH_LENGTH-1)); err == nil {
+		t.Error("Expected an error for a short hash")
+	}
+	if !h.IsSameAs(NewHash()) {
+		t.Error("Short input should leave the hash untouched")
+	}
+
+	header := new(ABlockHeader)
+	if err := header.UnmarshalBinary(make([]byte, 10)); err == nil {
+		t.Error("Expected an error for a header with a short chain id")
+	}
+}
+
+func TestHashTruncate(t *testing.T) {
+	h := Sha([]byte("abc"))
+	if !bytes.Equal(h.Truncate(8), h.Bytes()[:8]) {
+		t.Errorf("Unexpected truncated hash %x", h.Truncate(8))
+	}
+	if h.TruncatedHex(8) != h.String()[:16] {
+		t.Errorf("Unexpected truncated hex %v", h.TruncatedHex(8))
+	}
+
+	for _, n := range []int{-1, HASH_LENGTH + 1} {
+		func() {
+			defer func() {
+				r := recover()
+				if msg, ok := r.(string); !ok || !strings.HasPrefix(msg, "Cannot truncate a hash") {
+					t.Errorf("Expected an explicit panic when truncating to %v bytes, got %v", n, r)
+				}
+			}()
+			h.Truncate(n)
+		}()
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrIdentityKeyNotFound = errors.New("No key for the identity at this height")
+
+// Key history of the server identities, so that signatures can be checked
+// against the key that was valid at a given directory block height rather
+// than the current one
+type IdentityRegistry struct {
+	keys  map[[HASH_LENGTH]byte][]identityKey // Sorted by height
+	mutex sync.RWMutex
+}
+
+// Key of an identity, valid from Height until the next key of the identity
+type identityKey struct {
+	Height uint32
+	Key    PublicKey
+}
+
+func NewIdentityRegistry() *IdentityRegistry {
+	r := new(IdentityRegistry)
+	r.keys = make(map[[HASH_LENGTH]byte][]identityKey)
+	return r
+}
+
+// Record key as the key of identity from height on, replacing any key
+// already recorded at the same height
+func (r *IdentityRegistry) AddKey(identity *Hash, height uint32, key PublicKey) error {
+	if identity == nil || key.Key == nil {
+		return errors.New("Identity and key cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	keys := r.keys[identity.bytes]
+	i := sort.Search(len(keys), func(i int) bool { return keys[i].Height >= height })
+	if i < len(keys) && keys[i].Height == height {
+		keys[i].Key = key
+		return nil
+	}
+	keys = append(keys, identityKey{})
+	copy(keys[i+1:], keys[i:])
+	keys[i] = identityKey{Height: height, Key: key}
+	r.keys[identity.bytes] = keys
+	return nil
+}
+
+// Return the key of identity valid at height
+func (r *IdentityRegistry) KeyAt(identity *Hash, height uint32) (*PublicKey, error) {
+	if identity == nil {
+		return nil, errors.New("Identity cannot be nil")
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := r.keys[identity.bytes]
+	i := sort.Search(len(keys), func(i int) bool { return keys[i].Height > height })
+	if i == 0 {
+		return nil, ErrIdentityKeyNotFound
+	}
+	key := keys[i-1].Key
+	return &key, nil
+}
@@ -0,0 +1,80 @@
+package common_test
+
+import (
+	"testing"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+func TestIdentityRegistryKeyAt(t *testing.T) {
+	oldKey := new(PrivateKey)
+	newKey := new(PrivateKey)
+	if err := oldKey.GenerateKey(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := newKey.GenerateKey(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	identity := Sha([]byte("identity"))
+	registry := NewIdentityRegistry()
+	registry.AddKey(identity, 100, newKey.Pub)
+	registry.AddKey(identity, 10, oldKey.Pub)
+
+	if _, err := registry.KeyAt(identity, 9); err != ErrIdentityKeyNotFound {
+		t.Errorf("Expected ErrIdentityKeyNotFound, got %v", err)
+	}
+	if key, err := registry.KeyAt(identity, 99); err != nil || key.String() != oldKey.Pub.String() {
+		t.Errorf("Expected the old key at height 99: %v", err)
+	}
+	if key, err := registry.KeyAt(identity, 100); err != nil || key.String() != newKey.Pub.String() {
+		t.Errorf("Expected the new key at height 100: %v", err)
+	}
+
+	// A signature made with the old key before the rotation
+	prevDBHeader := newTestDBlockHeader()
+	headerBytes, err := prevDBHeader.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	entry := NewDBSignatureEntry(identity, oldKey.Sign(headerBytes))
+
+	if ok, err := entry.VerifySignature(prevDBHeader, nil, 0); !ok || err != nil {
+		t.Errorf("Signature does not verify against its own key: %v", err)
+	}
+	if ok, err := entry.VerifySignature(prevDBHeader, registry, 50); !ok || err != nil {
+		t.Errorf("Signature does not verify at height 50: %v", err)
+	}
+	if ok, err := entry.VerifySignature(prevDBHeader, registry, 150); ok || err != nil {
+		t.Errorf("Expected the signature to fail after the rotation: %v", err)
+	}
+}
+
+func TestDBSignatureEntryValidateKeyBinding(t *testing.T) {
+	key := new(PrivateKey)
+	other := new(PrivateKey)
+	if err := key.GenerateKey(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := other.GenerateKey(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	identity := Sha([]byte("identity"))
+	registry := NewIdentityRegistry()
+	registry.AddKey(identity, 10, key.Pub)
+
+	prevDBHeaderHash := Sha([]byte("previous directory block header"))
+	entry := NewDBSignatureEntry(identity, key.Sign(prevDBHeaderHash.Bytes()))
+	if err := entry.ValidateKeyBinding(registry, 10); err != nil {
+		t.Errorf("%v", err)
+	}
+	if err := entry.ValidateKeyBinding(registry, 5); err == nil {
+		t.Error("Expected an error before the identity had a key")
+	}
+
+	forged := NewDBSignatureEntry(identity, other.Sign(prevDBHeaderHash.Bytes()))
+	if err := forged.ValidateKeyBinding(registry, 10); err == nil {
+		t.Error("Expected an error for a key not registered to the identity")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+// Package msgpack serializes blocks with MessagePack for RPC between nodes.
+package msgpack
+
+import (
+	"github.com/FactomProject/FactomCode/common"
+	"github.com/ugorji/go/codec"
+)
+
+var handle codec.MsgpackHandle
+
+// Serialize the admin block to MessagePack, in the layout of
+// common.AdminBlockMsg
+func SerializeToMessagePack(b *common.AdminBlock) (data []byte, err error) {
+	msg, err := common.NewAdminBlockMsg(b)
+	if err != nil {
+		return nil, err
+	}
+
+	err = codec.NewEncoderBytes(&data, &handle).Encode(msg)
+	return data, err
+}
+
+// Read in an admin block serialized by SerializeToMessagePack
+func DeserializeAdminBlockFromMessagePack(data []byte) (*common.AdminBlock, error) {
+	msg := new(common.AdminBlockMsg)
+	if err := codec.NewDecoderBytes(data, &handle).Decode(msg); err != nil {
+		return nil, err
+	}
+	return msg.AdminBlock()
+}
@@ -0,0 +1,67 @@
+package msgpack_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/FactomProject/FactomCode/common"
+	. "github.com/FactomProject/FactomCode/common/msgpack"
+)
+
+func createAdminBlock(t testing.TB) *common.AdminBlock {
+	chain := new(common.AdminChain)
+	chain.ChainID = common.NewHash()
+	chain.ChainID.SetBytes(common.ADMIN_CHAINID)
+
+	block, err := common.CreateAdminBlock(chain, nil, 20)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	sig := common.UnmarshalBinarySignature(make([]byte, 96))
+	for i := 0; i < 10; i++ {
+		block.AddABEntry(common.NewDBSignatureEntry(common.NewHash(), sig))
+		block.AddEndOfMinuteMarker(byte(i + 1))
+	}
+	return block
+}
+
+func TestAdminBlockMessagePack(t *testing.T) {
+	block := createAdminBlock(t)
+
+	data, err := SerializeToMessagePack(block)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	block2, err := DeserializeAdminBlockFromMessagePack(data)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	b1, err := block.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	b2, err := block2.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(b1, b2) {
+		t.Error("Decoded block differs from the original")
+	}
+}
+
+func BenchmarkSerializeToMessagePack(b *testing.B) {
+	block := createAdminBlock(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SerializeToMessagePack(block)
+	}
+}
+
+func BenchmarkAdminBlockMarshalBinary(b *testing.B) {
+	block := createAdminBlock(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		block.MarshalBinary()
+	}
+}
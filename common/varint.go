@@ -30,15 +30,23 @@ func DecodeVarInt(data []byte) (uint64, []byte) {
 
 // Encode an integer as a variable int into the given data buffer.
 func EncodeVarInt(out *bytes.Buffer, v uint64) error {
+	var scratch [10]byte
+	out.Write(AppendVarInt(scratch[:0], v))
+	return nil
+}
+
+// Append an integer encoded as a variable int to dst and return the
+// extended slice.
+func AppendVarInt(dst []byte, v uint64) []byte {
 	if v == 0 {
-		out.WriteByte(0)
+		dst = append(dst, 0)
 	}
 	h := v
 	start := false
 
 	if 0x8000000000000000&h != 0 { // Deal with the high bit set; Zero
-		out.WriteByte(0x81) // doesn't need this, only when set.
-		start = true        // Going the whole 10 byte path!
+		dst = append(dst, 0x81) // doesn't need this, only when set.
+		start = true            // Going the whole 10 byte path!
 	}
 
 	for i := 0; i < 9; i++ {
@@ -50,12 +58,12 @@ func EncodeVarInt(out *bytes.Buffer, v uint64) error {
 			} else {
 				b = b & 0x7F
 			}
-			out.WriteByte(b)
+			dst = append(dst, b)
 		}
 		h = h << 7
 	}
 
-	return nil
+	return dst
 }
 
 func VarIntLength(v uint64) uint64 {
@@ -148,7 +148,7 @@ type Db interface {
 	FetchABlockByHeight(height uint32) (aBlock *common.AdminBlock, err error)
 
 	// FetchAllABlocks gets all of the admin blocks
-	FetchAllABlocks() (aBlocks []common.AdminBlock, err error)
+	FetchAllABlocks() (aBlocks []*common.AdminBlock, err error)
 
 	// ProcessABlockBatch inserts the AdminBlock
 	ProcessFBlockBatch(block.IFBlock) error
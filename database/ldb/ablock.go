@@ -2,8 +2,6 @@ package ldb
 
 import (
 	//	"errors"
-	"bytes"
-	"encoding/binary"
 	"fmt"
 
 	"github.com/FactomProject/FactomCode/common"
@@ -64,10 +62,7 @@ func (db *LevelDb) ProcessABlockMultiBatch(block *common.AdminBlock) error {
 
 	// Insert the admin block number cross reference
 	key = []byte{byte(TBL_AB_NUM)}
-	key = append(key, common.ADMIN_CHAINID...)
-	bytes := make([]byte, 4)
-	binary.BigEndian.PutUint32(bytes, block.Header.DBHeight)
-	key = append(key, bytes...)
+	key = append(key, common.AdminBlockHeightKey(common.ADMIN_CHAINID, block.Header.DBHeight)...)
 	db.lbatch.Put(key, abHash.Bytes())
 
 	// Update the chain head reference
@@ -100,10 +95,7 @@ func (db *LevelDb) FetchABlockByHash(aBlockHash *common.Hash) (aBlock *common.Ad
 // FetchABlockByHeight gets an admin block by hash from the database.
 func (db *LevelDb) FetchABlockByHeight(height uint32) (aBlock *common.AdminBlock, err error) {
 	var key = []byte{byte(TBL_AB_NUM)}
-	var buf bytes.Buffer
-	binary.Write(&buf, binary.BigEndian, height)
-	key = append(key, common.ADMIN_CHAINID...)
-	key = append(key, buf.Bytes()...)
+	key = append(key, common.AdminBlockHeightKey(common.ADMIN_CHAINID, height)...)
 
 	var data []byte
 	db.dbLock.RLock()
@@ -122,17 +114,17 @@ func (db *LevelDb) FetchABlockByHeight(height uint32) (aBlock *common.AdminBlock
 }
 
 // FetchAllABlocks gets all of the admin blocks
-func (db *LevelDb) FetchAllABlocks() (aBlocks []common.AdminBlock, err error) {
+func (db *LevelDb) FetchAllABlocks() (aBlocks []*common.AdminBlock, err error) {
 	db.dbLock.RLock()
 	defer db.dbLock.RUnlock()
 	var fromkey = []byte{byte(TBL_AB)}   // Table Name (1 bytes)						// Timestamp  (8 bytes)
 	var tokey = []byte{byte(TBL_AB + 1)} // Table Name (1 bytes)
 	var iter iterator.Iterator
-	aBlockSlice := make([]common.AdminBlock, 0, 10)
+	aBlockSlice := make([]*common.AdminBlock, 0, 10)
 	iter = db.lDb.NewIterator(&util.Range{Start: fromkey, Limit: tokey}, db.ro)
 
 	for iter.Next() {
-		var aBlock common.AdminBlock
+		aBlock := new(common.AdminBlock)
 		_, err := aBlock.UnmarshalBinaryData(iter.Value())
 		if err != nil {
 			return nil, err
@@ -141,9 +141,10 @@ func initAChain() {
 		if uint32(i) != aBlocks[i].Header.DBHeight {
 			panic(errors.New("BlockID does not equal index for chain:" + achain.ChainID.String() + " block:" + fmt.Sprintf("%v", aBlocks[i].Header.DBHeight)))
 		}
-		if !validateDBSignature(&aBlocks[i], dchain) {
+		if !validateDBSignature(aBlocks[i], dchain) {
 			panic(errors.New("No valid signature found in Admin Block = " + fmt.Sprintf("%s\n", spew.Sdump(aBlocks[i]))))
 		}
+		achain.AddABlockToAChain(aBlocks[i])
 	}
 
 	//Create an empty block and append to the chain
@@ -154,7 +155,7 @@ func initAChain() {
 	} else {
 		// Entry Credit Chain should have the same height as the dir chain
 		achain.NextBlockHeight = dchain.NextDBHeight
-		achain.NextBlock, _ = common.CreateAdminBlock(achain, &aBlocks[achain.NextBlockHeight-1], 10)
+		achain.NextBlock, _ = common.CreateAdminBlock(achain, aBlocks[achain.NextBlockHeight-1], 10)
 	}
 
 	exportAChain(achain)
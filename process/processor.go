@@ -1127,6 +1127,8 @@ func newAdminBlock(chain *common.AdminChain) *common.AdminBlock {
 	if err != nil {
 		panic(err)
 	}
+	block.Seal()
+	chain.Publish(common.BlockSealed, block)
 
 	// Create the block and add a new block for new coming entries
 	chain.BlockMutex.Lock()
@@ -1136,6 +1138,7 @@ func newAdminBlock(chain *common.AdminChain) *common.AdminBlock {
 		panic(err)
 	}
 	chain.BlockMutex.Unlock()
+	chain.AddABlockToAChain(block)
 
 	//Store the block in db
 	db.ProcessABlockBatch(block)
@@ -1245,10 +1248,8 @@ func SignDirectoryBlock() error {
 	if nodeMode == common.SERVER_NODE && dchain.NextDBHeight > 0 {
 		// get the previous directory block from db
 		dbBlock, _ := db.FetchDBlockByHeight(dchain.NextDBHeight - 1)
-		dbHeaderBytes, _ := dbBlock.Header.MarshalBinary()
 		identityChainID := common.NewHash() // 0 ID for milestone 1
-		sig := serverPrivKey.Sign(dbHeaderBytes)
-		achain.NextBlock.AddABEntry(common.NewDBSignatureEntry(identityChainID, sig))
+		return achain.NextBlock.AddDBSignature(identityChainID, serverPrivKey.Key[:], dbBlock.Header)
 	}
 	return nil
 }
@@ -98,6 +98,10 @@ func processABlock(msg *wire.MsgABlock) error {
 		return errors.New("Server received msg:" + msg.Command())
 	}
 
+	if err := msg.ABlk.ValidateSizeAtHeight(); err != nil {
+		return err
+	}
+
 	//Add it to mem pool before saving it in db
 	abHash, err := msg.ABlk.PartialHash()
 	if err != nil {
@@ -314,6 +318,7 @@ func storeBlocksFromMemPool(b *common.DirectoryBlock, fMemPool *ftmMemPool, db d
 			if err != nil {
 				return err
 			}
+			achain.AddABlockToAChain(aBlkMsg.ABlk)
 			// for debugging
 			exportABlock(aBlkMsg.ABlk)
 		case fchain.ChainID.String():
@@ -35,7 +35,7 @@ func (f ByECBlockIDAccending) Swap(i, j int) {
 
 //------------------------------------------------
 // ABlock array sorting implementation - accending
-type ByABlockIDAccending []common.AdminBlock
+type ByABlockIDAccending []*common.AdminBlock
 
 func (f ByABlockIDAccending) Len() int {
 	return len(f)